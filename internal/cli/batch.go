@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+)
+
+// assumeYes is bound to --yes/--all on the commands that walk directories.
+var assumeYes bool
+
+// jobs is bound to --jobs; zero picks a default for the command.
+var jobs int
+
+type batchAnswer int
+
+const (
+	answerYes batchAnswer = iota
+	answerNo
+	answerAll
+	answerQuit
+)
+
+type batchStats struct {
+	processed int
+	skipped   int
+	failed    int
+	// written lists the files that were modified on disk.
+	written  []string
+	findings int
+}
+
+// runBatch applies fn to each file, prompting before every one unless --yes
+// was given or stdin is not a terminal. verb is used in the prompt, e.g.
+// "Process" or "Clear comments from". Once prompting stops, the remaining
+// files are handed to a pool of workers; reports are always flushed in scan
+// order. With a single worker files run one after another on the calling
+// goroutine, and a report asking to stop ends the batch.
+func runBatch(files []fileops.FileInfo, verb string, workers int, fn func(path string) *fileReport) batchStats {
+	var stats batchStats
+
+	prompt := !assumeYes && isInteractive()
+	if !assumeYes && !prompt {
+		statusln("stdin is not a terminal, processing all files without prompting.")
+		statusln()
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	i := 0
+	for ; prompt && i < len(files); i++ {
+		file := files[i]
+		switch askFile(reader, verb, file.Name) {
+		case answerNo:
+			statusf("Skipped %s\n\n", file.Name)
+			stats.skipped++
+			continue
+		case answerAll:
+			prompt = false
+		case answerQuit:
+			stats.skipped += len(files) - i
+			statusf("Quit, skipped %d remaining files.\n\n", len(files)-i)
+			return stats
+		}
+		report := fn(file.Path)
+		stats.record(file, report)
+		if report.stop {
+			stats.stopped(len(files) - i - 1)
+			return stats
+		}
+	}
+
+	if workers <= 1 {
+		for ; i < len(files); i++ {
+			report := fn(files[i].Path)
+			stats.record(files[i], report)
+			if report.stop {
+				stats.stopped(len(files) - i - 1)
+				return stats
+			}
+		}
+		return stats
+	}
+
+	for _, result := range runPool(files[i:], workers, fn) {
+		stats.record(result.file, <-result.report)
+	}
+
+	return stats
+}
+
+type poolResult struct {
+	file   fileops.FileInfo
+	report chan *fileReport
+}
+
+// runPool starts fn on files with at most workers running at once. The
+// returned results are in the same order as files, each delivering its report
+// once that file is done.
+func runPool(files []fileops.FileInfo, workers int, fn func(path string) *fileReport) []poolResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]poolResult, len(files))
+	for i, file := range files {
+		results[i] = poolResult{file: file, report: make(chan *fileReport, 1)}
+	}
+
+	go func() {
+		sem := make(chan struct{}, workers)
+		for _, result := range results {
+			sem <- struct{}{}
+			go func(result poolResult) {
+				defer func() { <-sem }()
+				result.report <- fn(result.file.Path)
+			}(result)
+		}
+	}()
+
+	return results
+}
+
+func (s *batchStats) record(file fileops.FileInfo, report *fileReport) {
+	report.flush()
+	if report.err != nil {
+		statusf("Error processing %s: %v\n", file.Name, report.err)
+		s.failed++
+	} else if report.stop {
+		s.skipped++
+	} else {
+		s.processed++
+	}
+	if report.written {
+		s.written = append(s.written, file.Path)
+	}
+	s.findings += report.findings
+	statusln()
+}
+
+func (s *batchStats) stopped(remaining int) {
+	s.skipped += remaining
+	if remaining > 0 {
+		statusf("Stopped, skipped %d remaining files.\n\n", remaining)
+	}
+}
+
+func askFile(reader *bufio.Reader, verb, name string) batchAnswer {
+	for {
+		statusf("%s %s? (y/n/a/q): ", verb, name)
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+
+		switch input {
+		case "y", "yes":
+			return answerYes
+		case "n", "no":
+			return answerNo
+		case "a", "all":
+			return answerAll
+		case "q", "quit":
+			return answerQuit
+		}
+		if err != nil {
+			// stdin closed mid-prompt, stop rather than spin.
+			return answerQuit
+		}
+		statusln("  y = yes, n = no, a = yes to all remaining, q = quit")
+	}
+}
+
+func isInteractive() bool {
+	return term.IsTerminal(os.Stdin.Fd())
+}
+
+func (s batchStats) summary() string {
+	return fmt.Sprintf("%d processed, %d skipped, %d failed", s.processed, s.skipped, s.failed)
+}
+
+func (s batchStats) err() error {
+	if s.failed > 0 {
+		return fmt.Errorf("%d of %d files failed", s.failed, s.processed+s.skipped+s.failed)
+	}
+	return nil
+}
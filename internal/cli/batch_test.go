@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudboy-jh/annotr/internal/config"
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/llm"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+)
+
+// reverseClient answers the request for each function only once those for
+// the functions after it have been answered, so files commented concurrently
+// finish in reverse order. The request for fail gets an error.
+type reverseClient struct {
+	names    []string
+	fail     string
+	done     map[string]chan struct{}
+	mu       sync.Mutex
+	finished []string
+}
+
+func newReverseClient(names []string, fail string) *reverseClient {
+	c := &reverseClient{names: names, fail: fail, done: map[string]chan struct{}{}}
+	for _, name := range names {
+		c.done[name] = make(chan struct{})
+	}
+	return c
+}
+
+func (c *reverseClient) Complete(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	for i, name := range c.names {
+		if !strings.Contains(prompt, "func "+name+"()") {
+			continue
+		}
+		if i+1 < len(c.names) {
+			<-c.done[c.names[i+1]]
+		}
+		c.mu.Lock()
+		c.finished = append(c.finished, name)
+		c.mu.Unlock()
+		close(c.done[name])
+		if name == c.fail {
+			return nil, errors.New("model unavailable")
+		}
+		return &llm.CompletionResponse{Content: name + " does nothing."}, nil
+	}
+	return nil, errors.New("unexpected request")
+}
+
+func (c *reverseClient) Provider() string { return "fake" }
+
+// captureStdout runs fn with os.Stdout redirected and returns what it wrote.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	stdout := os.Stdout
+	os.Stdout = f
+	defer func() { os.Stdout = stdout }()
+	fn()
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRunBatchReportOrder(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"A", "B", "C", "D"}
+	var files []fileops.FileInfo
+	for _, name := range names {
+		file := strings.ToLower(name) + ".go"
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte("package x\n\nfunc "+name+"() {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, fileops.FileInfo{Path: path, Name: file})
+	}
+
+	client := newReverseClient(names, "B")
+	a := &annotator{
+		cfg:     config.DefaultConfig(),
+		client:  client,
+		strict:  true,
+		nesting: parser.NestingMembers,
+	}
+	assumeYes = true
+	defer func() { assumeYes = false }()
+
+	var stats batchStats
+	out := captureStdout(t, func() {
+		stats = runBatch(files, "Process", len(files), a.processFile)
+	})
+
+	if want := []string{"D", "C", "B", "A"}; !reflect.DeepEqual(client.finished, want) {
+		t.Fatalf("requests finished in order %v, want %v", client.finished, want)
+	}
+	reports := regexp.MustCompile(`(?m)^(Processing \w\.go|Error processing \w\.go)`).FindAllString(out, -1)
+	want := []string{"Processing a.go", "Processing b.go", "Error processing b.go", "Processing c.go", "Processing d.go"}
+	if !reflect.DeepEqual(reports, want) {
+		t.Errorf("reports = %q, want %q\n%s", reports, want, out)
+	}
+	if stats.processed != 3 || stats.failed != 1 || len(stats.written) != 3 {
+		t.Errorf("stats = %+v, want 3 processed and written, 1 failed", stats)
+	}
+	if err := stats.err(); err == nil || err.Error() != "1 of 4 files failed" {
+		t.Errorf("err() = %v, want 1 of 4 files failed", err)
+	}
+}
+
+func TestRunPoolOrder(t *testing.T) {
+	var files []fileops.FileInfo
+	done := map[string]chan struct{}{}
+	for _, name := range []string{"a", "b", "c"} {
+		files = append(files, fileops.FileInfo{Path: name, Name: name})
+		done[name] = make(chan struct{})
+	}
+
+	var mu sync.Mutex
+	var finished []string
+	results := runPool(files, len(files), func(path string) *fileReport {
+		for i, file := range files {
+			if file.Path == path && i+1 < len(files) {
+				<-done[files[i+1].Path]
+			}
+		}
+		mu.Lock()
+		finished = append(finished, path)
+		mu.Unlock()
+		close(done[path])
+		r := &fileReport{}
+		r.logf("%s\n", path)
+		return r
+	})
+
+	for i, result := range results {
+		if result.file != files[i] {
+			t.Errorf("result %d is for %s, want %s", i, result.file.Name, files[i].Name)
+		}
+		if got := (<-result.report).log.String(); got != files[i].Path+"\n" {
+			t.Errorf("result %d reports %q, want %q", i, got, files[i].Path+"\n")
+		}
+	}
+	if want := []string{"c", "b", "a"}; !reflect.DeepEqual(finished, want) {
+		t.Errorf("files finished in order %v, want %v", finished, want)
+	}
+}
@@ -1,14 +1,15 @@
 package cli
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/llm"
 	"github.com/cloudboy-jh/annotr/internal/parser"
 	"github.com/spf13/cobra"
 )
@@ -20,16 +21,29 @@ var clearCmd = &cobra.Command{
 
 Examples:
   annotr clear file.go       # Remove comments from a single file
-  annotr clear ./src         # Remove comments from all files in directory`,
+  annotr clear ./src         # Remove comments from all files in directory
+  annotr clear --yes ./src   # Same, without prompting for each file
+  annotr clear --generated-only ./src  # Only remove comments marked as generated by annotr
+  annotr clear --diff file.go  # Preview removals as a unified diff`,
 	Args: cobra.ExactArgs(1),
 	RunE: runClear,
 }
 
 func init() {
 	rootCmd.AddCommand(clearCmd)
+	clearCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print a unified diff instead of writing files")
+	clearCmd.Flags().BoolVar(&dryRun, "diff", false, "alias for --dry-run")
+	clearCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "process every file without prompting")
+	clearCmd.Flags().BoolVar(&assumeYes, "all", false, "alias for --yes")
+	clearCmd.Flags().BoolVar(&generatedOnly, "generated-only", false, "only remove comments annotr generated with --provenance")
+	clearCmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "number of files to process at once (default number of CPUs)")
 }
 
+// generatedOnly is bound to clear --generated-only.
+var generatedOnly bool
+
 func runClear(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
 	target := args[0]
 	info, err := os.Stat(target)
 	if err != nil {
@@ -39,36 +53,48 @@ func runClear(cmd *cobra.Command, args []string) error {
 	if info.IsDir() {
 		return clearDirectory(target)
 	}
-	return clearFile(target)
+	report := clearFile(target)
+	report.flush()
+	return report.err
 }
 
-func clearFile(path string) error {
+func clearFile(path string) *fileReport {
+	r := &fileReport{}
+
 	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return err
+		return r.fail(err)
 	}
 
 	if !parser.IsSupportedFile(absPath) {
-		return fmt.Errorf("unsupported file type: %s", filepath.Ext(path))
+		return r.fail(fmt.Errorf("unsupported file type: %s", filepath.Ext(path)))
 	}
 
-	fmt.Printf("Clearing comments from %s...\n", filepath.Base(path))
+	r.logf("Clearing comments from %s...\n", filepath.Base(path))
 
 	source, err := fileops.ReadFile(absPath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return r.fail(fmt.Errorf("failed to read file: %w", err))
 	}
 
-	cleaned, count := removeComments(source, filepath.Ext(absPath))
+	p, err := parser.NewParser(absPath)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	cleaned, count, err := removeComments(p, source, generatedOnly)
+	if err != nil {
+		return r.fail(fmt.Errorf("failed to parse file: %w", err))
+	}
 
 	if count > 0 {
-		if err := fileops.WriteFile(absPath, cleaned); err != nil {
-			return fmt.Errorf("failed to write file: %w", err)
+		if err := saveFile(r, absPath, source, cleaned); err != nil {
+			return r.fail(err)
 		}
 	}
 
-	fmt.Printf("✓ Removed %d comment blocks\n", count)
-	return nil
+	r.logf("✓ Removed %d comment blocks\n", count)
+	return r
 }
 
 func clearDirectory(dir string) error {
@@ -78,102 +104,172 @@ func clearDirectory(dir string) error {
 	}
 
 	if len(files) == 0 {
-		fmt.Println("No supported files found in directory.")
+		statusln("No supported files found in directory.")
 		return nil
 	}
 
-	reader := bufio.NewReader(os.Stdin)
-	processedCount := 0
+	workers := jobs
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	stats := runBatch(files, "Clear comments from", workers, clearFile)
 
-	for _, file := range files {
-		fmt.Printf("Clear comments from %s? (y/n): ", file.Name)
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(strings.ToLower(input))
+	statusf("Done! Cleared comments from %d of %d files (%s).\n", stats.processed, len(files), stats.summary())
+	return stats.err()
+}
+
+// removeComments deletes the comment nodes the parser finds in source, along
+// with Python docstrings, leaving tool directives in place. Comments on lines
+// of their own take the whole line with them, and the blank lines after them
+// when a blank line already comes before; trailing comments take the
+// whitespace before them. The rest of the file is left as it was. With
+// generatedOnly, only doc comments carrying an annotr provenance marker are
+// removed, inline comments included, and no other line changes.
+func removeComments(p *parser.Parser, source []byte, generatedOnly bool) ([]byte, int, error) {
+	comments, err := p.Comments(source)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		if input == "y" || input == "yes" {
-			if err := clearFile(file.Path); err != nil {
-				fmt.Printf("Error processing %s: %v\n", file.Name, err)
-			} else {
-				processedCount++
+	var generated []parser.CodeBlock
+	if generatedOnly {
+		blocks, err := p.Parse(source)
+		if err != nil {
+			return nil, 0, err
+		}
+		statements, err := p.ParseInline(source, blocks)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, block := range append(blocks, statements...) {
+			if _, ok := llm.ParseProvenance(block.DocComment); ok {
+				generated = append(generated, block)
 			}
-		} else {
-			fmt.Printf("Skipped %s\n", file.Name)
 		}
-		fmt.Println()
 	}
 
-	fmt.Printf("Done! Cleared comments from %d of %d files.\n", processedCount, len(files))
-	return nil
-}
-
-func removeComments(source []byte, ext string) ([]byte, int) {
-	content := string(source)
-	lines := strings.Split(content, "\n")
-	var result []string
+	var out bytes.Buffer
+	pos := 0
 	count := 0
-	inBlockComment := false
-
-	lineCommentPattern := getLineCommentPattern(ext)
-	blockStart, blockEnd := getBlockCommentPatterns(ext)
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	for i, c := range comments {
+		if isPreserved(comments, i, source, p.Language()) {
+			continue
+		}
+		if generatedOnly && !inGeneratedDoc(c, generated) {
+			continue
+		}
 
-		if blockStart != "" && blockEnd != "" {
-			if strings.HasPrefix(trimmed, blockStart) && strings.HasSuffix(trimmed, blockEnd) {
-				count++
-				continue
-			}
-			if strings.HasPrefix(trimmed, blockStart) {
-				inBlockComment = true
-				count++
-				continue
-			}
-			if inBlockComment {
-				if strings.HasSuffix(trimmed, blockEnd) || trimmed == blockEnd {
-					inBlockComment = false
-				}
-				continue
-			}
+		start, end := int(c.StartByte), int(c.EndByte)
+		lineStart := start
+		for lineStart > 0 && (source[lineStart-1] == ' ' || source[lineStart-1] == '\t') {
+			lineStart--
+		}
+		lineEnd := end
+		for lineEnd < len(source) && (source[lineEnd] == ' ' || source[lineEnd] == '\t' || source[lineEnd] == '\r') {
+			lineEnd++
 		}
+		ownLine := lineStart == 0 || source[lineStart-1] == '\n'
+		endsLine := lineEnd == len(source) || source[lineEnd] == '\n'
 
-		if lineCommentPattern != nil && lineCommentPattern.MatchString(trimmed) {
-			count++
+		replacement := ""
+		wholeLines := false
+		switch {
+		case c.OnlyStatement:
+			// Python needs at least one statement in a body.
+			replacement = "pass"
+		case ownLine && endsLine:
+			start, end = lineStart, lineEnd
+			if end < len(source) {
+				end++
+			}
+			wholeLines = true
+		case c.Docstring:
+			// A docstring sharing its line with code is left alone.
 			continue
+		case endsLine:
+			start, end = lineStart, lineEnd
+		case start > 0 && source[start-1] == ' ' && end < len(source) && source[end] == ' ':
+			// Inline comment between tokens: don't leave a double space.
+			end++
 		}
 
-		result = append(result, line)
+		out.Write(source[pos:start])
+		out.WriteString(replacement)
+		if wholeLines && !generatedOnly && endsWithBlankLine(out.Bytes()) {
+			// Don't leave two blank lines where the comment was.
+			end = skipBlankLines(source, end)
+			if end == len(source) {
+				// Nor a blank line at the end of the file.
+				text := out.Bytes()
+				n := len(bytes.TrimRight(text, " \t\r\n"))
+				if n > 0 {
+					n += bytes.IndexByte(text[n:], '\n') + 1
+				}
+				out.Truncate(n)
+			}
+		}
+		pos = end
+		count++
 	}
+	out.Write(source[pos:])
 
-	cleaned := strings.Join(result, "\n")
-	cleaned = removeExcessiveBlankLines(cleaned)
+	return out.Bytes(), count, nil
+}
 
-	return []byte(cleaned), count
+// endsWithBlankLine reports whether text is empty or its last complete line
+// is blank.
+func endsWithBlankLine(text []byte) bool {
+	text = bytes.TrimRight(bytes.TrimSuffix(text, []byte("\n")), " \t\r")
+	return len(text) == 0 || text[len(text)-1] == '\n'
 }
 
-func getLineCommentPattern(ext string) *regexp.Regexp {
-	switch ext {
-	case ".go", ".js", ".ts", ".tsx":
-		return regexp.MustCompile(`^//`)
-	case ".py":
-		return regexp.MustCompile(`^#`)
-	default:
-		return nil
+// skipBlankLines returns the offset of the first line at or after pos that
+// has anything but whitespace on it, or len(source).
+func skipBlankLines(source []byte, pos int) int {
+	for pos < len(source) {
+		next := bytes.IndexByte(source[pos:], '\n')
+		if next < 0 {
+			if len(bytes.TrimSpace(source[pos:])) == 0 {
+				return len(source)
+			}
+			return pos
+		}
+		if len(bytes.TrimSpace(source[pos:pos+next])) != 0 {
+			return pos
+		}
+		pos += next + 1
 	}
+	return pos
 }
 
-func getBlockCommentPatterns(ext string) (string, string) {
-	switch ext {
-	case ".go", ".js", ".ts", ".tsx":
-		return "/*", "*/"
-	case ".py":
-		return `"""`, `"""`
-	default:
-		return "", ""
+func inGeneratedDoc(c parser.Comment, blocks []parser.CodeBlock) bool {
+	for _, block := range blocks {
+		if c.StartLine >= block.DocStartLine && c.EndLine <= block.DocEndLine {
+			return true
+		}
 	}
+	return false
 }
 
-func removeExcessiveBlankLines(content string) string {
-	re := regexp.MustCompile(`\n{3,}`)
-	return re.ReplaceAllString(content, "\n\n")
+// isPreserved reports whether clear must leave comments[i] alone: tool
+// directives and, in Go, the cgo preamble directly above import "C".
+func isPreserved(comments []parser.Comment, i int, source []byte, language string) bool {
+	if parser.IsDirective(comments[i].Text, language) {
+		return true
+	}
+	if language != "go" {
+		return false
+	}
+
+	// Follow the run of adjacent comments down to whatever comes next.
+	for ; i < len(comments); i++ {
+		rest := strings.TrimLeft(string(source[comments[i].EndByte:]), " \t\r\n")
+		if strings.HasPrefix(rest, `import "C"`) {
+			return true
+		}
+		if i+1 == len(comments) || strings.TrimSpace(string(source[comments[i].EndByte:comments[i+1].StartByte])) != "" {
+			return false
+		}
+	}
+	return false
 }
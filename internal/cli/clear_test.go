@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/cloudboy-jh/annotr/internal/llm"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+)
+
+func TestRemoveComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		source   string
+		want     string
+		count    int
+	}{
+		{
+			name:     "go directives",
+			filename: "x.go",
+			source: "//go:build linux\n\n// Package x does things.\npackage x\n\n//go:generate stringer -type=Kind\n\n" +
+				"// Kind is a kind.\ntype Kind int\n\n//nolint:unused\nfunc f() {}\n",
+			want:  "//go:build linux\n\npackage x\n\n//go:generate stringer -type=Kind\n\ntype Kind int\n\n//nolint:unused\nfunc f() {}\n",
+			count: 2,
+		},
+		{
+			name:     "python shebang and pragmas",
+			filename: "x.py",
+			source: "#!/usr/bin/env python3\n# -*- coding: utf-8 -*-\n# Helpers.\nimport os  # type: ignore\n\n" +
+				"x = 1  # pylint: disable=invalid-name\n",
+			want:  "#!/usr/bin/env python3\n# -*- coding: utf-8 -*-\nimport os  # type: ignore\n\nx = 1  # pylint: disable=invalid-name\n",
+			count: 1,
+		},
+		{
+			name:     "comment-like text in strings",
+			filename: "x.py",
+			source:   "s = \"\"\"\n# not a comment\n\"\"\"\nu = \"// nor this\"  # but this is\n",
+			want:     "s = \"\"\"\n# not a comment\n\"\"\"\nu = \"// nor this\"\n",
+			count:    1,
+		},
+		{
+			name:     "comment-like text in go strings",
+			filename: "x.go",
+			source:   "package x\n\nconst s = `\n// not a comment\n/* nor this */\n`\n",
+			want:     "package x\n\nconst s = `\n// not a comment\n/* nor this */\n`\n",
+		},
+		{
+			name:     "trailing same-line comments",
+			filename: "x.go",
+			source:   "package x\n\nfunc f() int {\n\tx := 1 // one\n\ty := /* two */ 2\n\treturn x + y\t// sum\n}\n",
+			want:     "package x\n\nfunc f() int {\n\tx := 1\n\ty := 2\n\treturn x + y\n}\n",
+			count:    3,
+		},
+		{
+			name:     "blank lines between comments and code",
+			filename: "x.go",
+			source:   "package x\n\n// Section.\n\nfunc f() {}\n\n\n\nfunc g() {}\n\n// trailing\n",
+			want:     "package x\n\nfunc f() {}\n\n\n\nfunc g() {}\n",
+			count:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parser.NewParser(tt.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, count, err := removeComments(p, []byte(tt.source), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("removeComments() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if count != tt.count {
+				t.Errorf("removeComments() removed %d comments, want %d", count, tt.count)
+			}
+		})
+	}
+}
+
+func TestRemoveCommentsGeneratedOnly(t *testing.T) {
+	marker := llm.Provenance{Model: "m", Hash: "abc123"}.Marker()
+	kept := "package x\n\n// Human wrote this.\n\n\n\nfunc A() {}\n\n"
+	source := kept + "// B does b.\n// " + marker + "\nfunc B() {\n\tb() // b\n}\n\n// C does c.\nfunc C() {}\n"
+	want := kept + "func B() {\n\tb() // b\n}\n\n// C does c.\nfunc C() {}\n"
+
+	p, err := parser.NewParser("x.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, count, err := removeComments(p, []byte(source), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("removeComments() =\n%s\nwant\n%s", got, want)
+	}
+	if count != 2 {
+		t.Errorf("removeComments() removed %d comments, want 2", count)
+	}
+}
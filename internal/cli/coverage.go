@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudboy-jh/annotr/internal/config"
+	"github.com/cloudboy-jh/annotr/internal/coverage"
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	coverageFormat       string
+	coverageMin          float64
+	coverageExportedOnly bool
+)
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [file or directory]",
+	Short: "Report documentation coverage",
+	Long: `Report which commentable code blocks already have a doc comment.
+
+No LLM is called, so this is cheap enough to run in CI.
+
+Examples:
+  annotr coverage                          # Text report for the current directory
+  annotr coverage --exported-only ./pkg    # Only count exported symbols
+  annotr coverage --min 80                 # Exit non-zero below 80% coverage
+  annotr coverage --format junit > docs.xml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCoverage,
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+	coverageCmd.Flags().StringVarP(&coverageFormat, "format", "f", "text", "output format: "+strings.Join(coverage.Formats, ", "))
+	coverageCmd.Flags().Float64Var(&coverageMin, "min", 0, "minimum coverage percentage; exit non-zero below it")
+	coverageCmd.Flags().BoolVar(&coverageExportedOnly, "exported-only", false, "only count exported symbols")
+	coverageCmd.Flags().StringVar(&nesting, "nesting", "", "which nested blocks to count: top, members or all (default members)")
+}
+
+func runCoverage(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	// Coverage works without a config, but honours its nesting policy.
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	policy, err := nestingPolicy(cfg)
+	if err != nil {
+		return err
+	}
+
+	target := "."
+	if len(args) > 0 {
+		target = args[0]
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", target, err)
+	}
+
+	var files []fileops.FileInfo
+	if info.IsDir() {
+		files, err = fileops.ScanDirectory(target)
+		if err != nil {
+			return fmt.Errorf("failed to scan directory: %w", err)
+		}
+	} else {
+		if !parser.IsSupportedFile(target) {
+			return fmt.Errorf("unsupported file type: %s", filepath.Ext(target))
+		}
+		files = []fileops.FileInfo{{Path: target, Name: info.Name()}}
+	}
+
+	report := coverage.Analyze(files, coverageExportedOnly, policy)
+	if err := coverage.Write(os.Stdout, report, coverageFormat); err != nil {
+		return err
+	}
+
+	for _, f := range report.Files {
+		if f.Error != "" {
+			return fmt.Errorf("could not analyse %s: %s", f.Path, f.Error)
+		}
+	}
+	if report.Percent < coverageMin {
+		return fmt.Errorf("documentation coverage %.1f%% is below the minimum of %.1f%%", report.Percent, coverageMin)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/cloudboy-jh/annotr/internal/config"
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/git"
+	"github.com/cloudboy-jh/annotr/internal/llm"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+const hookMarker = "# annotr pre-commit hook"
+
+const hookScript = `#!/bin/sh
+` + hookMarker + `
+# Installed by 'annotr hook install'; remove with 'annotr hook uninstall'.
+exec annotr hook run
+`
+
+const preCommitSnippet = `# Add to .pre-commit-config.yaml
+repos:
+  - repo: https://github.com/cloudboy-jh/annotr
+    rev: %s
+    hooks:
+      - id: annotr
+`
+
+// pseudoVersion matches the timestamp and commit hash Go stamps into builds
+// from a commit that is not a tagged release.
+var pseudoVersion = regexp.MustCompile(`\d{14}-[0-9a-f]{12}`)
+
+// snippetRev is the rev the pre-commit snippet pins: the release this binary
+// was built from, or a placeholder for builds without one.
+func snippetRev() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v := info.Main.Version; strings.HasPrefix(v, "v") && !pseudoVersion.MatchString(v) && !strings.HasSuffix(v, "+dirty") {
+			return v
+		}
+	}
+	return "<release tag>  # e.g. the latest from https://github.com/cloudboy-jh/annotr/releases"
+}
+
+var hookForce bool
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git pre-commit hook",
+	Long: `Manage a git pre-commit hook that comments staged code before each commit.
+
+Examples:
+  annotr hook install    # Write .git/hooks/pre-commit (honours core.hooksPath)
+  annotr hook uninstall  # Remove it again
+  annotr hook run        # What the hook runs: annotate staged changes and re-stage
+  annotr hook snippet    # Print a .pre-commit-config.yaml entry for the pre-commit framework`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit hook",
+	Args:  cobra.NoArgs,
+	RunE:  runHookInstall,
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the pre-commit hook",
+	Args:  cobra.NoArgs,
+	RunE:  runHookUninstall,
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Annotate staged changes and re-stage the modified files",
+	Args:  cobra.NoArgs,
+	RunE:  runHookRun,
+}
+
+var hookSnippetCmd = &cobra.Command{
+	Use:   "snippet",
+	Short: "Print a pre-commit framework configuration snippet",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf(preCommitSnippet, snippetRev())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookRunCmd, hookSnippetCmd)
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "replace an existing pre-commit hook, keeping a backup")
+}
+
+func hookPath() (string, error) {
+	dir, err := git.HooksDir(".")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pre-commit"), nil
+}
+
+func isAnnotrHook(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(data), hookMarker)
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	path, err := hookPath()
+	if err != nil {
+		return err
+	}
+
+	if fileops.FileExists(path) && !isAnnotrHook(path) {
+		if !hookForce {
+			return fmt.Errorf("a pre-commit hook already exists at %s; rerun with --force to replace it", path)
+		}
+		if err := os.Rename(path, path+".annotr-backup"); err != nil {
+			return fmt.Errorf("failed to back up existing hook: %w", err)
+		}
+		fmt.Printf("  Existing hook saved to %s\n", path+".annotr-backup")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(hookScript), 0755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	fmt.Println("✓ Pre-commit hook installed")
+	fmt.Printf("  Saved to: %s\n", path)
+	return nil
+}
+
+func runHookUninstall(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	path, err := hookPath()
+	if err != nil {
+		return err
+	}
+
+	if !fileops.FileExists(path) {
+		fmt.Println("No pre-commit hook installed.")
+		return nil
+	}
+	if !isAnnotrHook(path) {
+		return fmt.Errorf("%s was not installed by annotr, leaving it alone", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove hook: %w", err)
+	}
+	fmt.Println("✓ Pre-commit hook removed")
+
+	backup := path + ".annotr-backup"
+	if fileops.FileExists(backup) {
+		if err := os.Rename(backup, path); err != nil {
+			return fmt.Errorf("failed to restore previous hook: %w", err)
+		}
+		fmt.Println("  Restored previous hook")
+	}
+	return nil
+}
+
+func runHookRun(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("annotr is not configured; run 'annotr init' or commit with --no-verify")
+	}
+
+	if !config.IsProviderReachable(cfg.DefaultProvider) {
+		return fmt.Errorf("%s is unreachable, commit aborted; start it or commit with --no-verify", cfg.DefaultProvider)
+	}
+
+	root, err := git.RepoRoot(".")
+	if err != nil {
+		return err
+	}
+	// Re-staging a partially staged file would sweep the unstaged hunks
+	// into the commit, so stagedChanges leaves those files out.
+	staged, err := stagedChanges(root)
+	if err != nil {
+		return err
+	}
+
+	paths := staged.Paths()
+	sort.Strings(paths)
+
+	var files []fileops.FileInfo
+	for _, path := range paths {
+		if !parser.IsSupportedFile(path) {
+			continue
+		}
+		// Commenting is best effort: a staged path annotr cannot find in
+		// the working tree is not a reason to block the commit.
+		if !fileops.FileExists(path) {
+			statusf("Skipping %s: not found in the working tree\n", filepath.Base(path))
+			continue
+		}
+		files = append(files, fileops.FileInfo{Path: path, Name: filepath.Base(path)})
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	policy, err := nestingPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	if err := llm.ValidateDocFormats(cfg.DocFormats); err != nil {
+		return err
+	}
+	if err := validateSQL(cfg, cfg.SQLCommentOn); err != nil {
+		return err
+	}
+
+	workers := llm.DefaultConcurrency(cfg.DefaultProvider)
+	a := &annotator{
+		cfg:           cfg,
+		client:        newClient(cfg, workers),
+		changes:       staged,
+		strict:        true,
+		nesting:       policy,
+		inline:        cfg.Inline,
+		inlineDensity: cfg.InlineDensity,
+		commentOn:     cfg.SQLCommentOn,
+	}
+
+	assumeYes = true
+	stats := runBatch(files, "Process", workers, a.processFile)
+
+	if err := git.Add(root, stats.written...); err != nil {
+		return fmt.Errorf("failed to re-stage files: %w", err)
+	}
+	if stats.failed > 0 {
+		return fmt.Errorf("%s, commit aborted; fix the errors above or commit with --no-verify", stats.err())
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestHookInstallUninstall(t *testing.T) {
+	dir := newRepo(t)
+	path := filepath.Join(dir, ".git", "hooks", "pre-commit")
+
+	if err := runHookInstall(hookInstallCmd, nil); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if !isAnnotrHook(path) {
+		t.Fatalf("install did not write an annotr hook to %s", path)
+	}
+	if err := runHookInstall(hookInstallCmd, nil); err != nil {
+		t.Fatalf("reinstall over our own hook: %v", err)
+	}
+
+	if err := runHookUninstall(hookUninstallCmd, nil); err != nil {
+		t.Fatalf("uninstall: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("uninstall left %s behind", path)
+	}
+}
+
+func TestHookInstallKeepsExistingHook(t *testing.T) {
+	dir := newRepo(t)
+	path := filepath.Join(dir, ".git", "hooks", "pre-commit")
+	existing := "#!/bin/sh\nmake lint\n"
+	if err := os.WriteFile(path, []byte(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	hookForce = false
+	if err := runHookInstall(hookInstallCmd, nil); err == nil {
+		t.Fatal("install replaced a foreign hook without --force")
+	}
+	if err := runHookUninstall(hookUninstallCmd, nil); err == nil {
+		t.Fatal("uninstall removed a foreign hook")
+	}
+
+	hookForce = true
+	defer func() { hookForce = false }()
+	if err := runHookInstall(hookInstallCmd, nil); err != nil {
+		t.Fatalf("install --force: %v", err)
+	}
+	if err := runHookUninstall(hookUninstallCmd, nil); err != nil {
+		t.Fatalf("uninstall: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != existing {
+		t.Errorf("uninstall restored %q, want %q", data, existing)
+	}
+}
+
+func TestHookHonoursHooksPath(t *testing.T) {
+	dir := newRepo(t)
+	gitCmd(t, dir, "config", "core.hooksPath", "githooks")
+
+	if err := runHookInstall(hookInstallCmd, nil); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if !isAnnotrHook(filepath.Join(dir, "githooks", "pre-commit")) {
+		t.Error("install ignored core.hooksPath")
+	}
+}
+
+// newRepo creates a git repository isolated from the user's configuration
+// and makes it the working directory for the test.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, dir, "init", "-q")
+	t.Chdir(dir)
+	return dir
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+)
+
+// dryRun is bound to --dry-run/--diff on the commands that rewrite files.
+var dryRun bool
+
+// statusOut returns where progress messages go. In dry-run mode stdout is
+// reserved for the diff so it can be piped straight into git apply.
+func statusOut() io.Writer {
+	if dryRun {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func statusf(format string, a ...any) {
+	fmt.Fprintf(statusOut(), format, a...)
+}
+
+func statusln(a ...any) {
+	fmt.Fprintln(statusOut(), a...)
+}
+
+// fileReport buffers everything processing a single file produces, so files
+// handled concurrently can still be reported in scan order.
+type fileReport struct {
+	log     strings.Builder
+	diff    string
+	err     error
+	written bool
+	// findings counts problems reported without failing the file, such as
+	// outdated comments found by refresh --check.
+	findings int
+	// stop asks runBatch not to start any further files.
+	stop bool
+}
+
+func (r *fileReport) logf(format string, a ...any) {
+	fmt.Fprintf(&r.log, format, a...)
+}
+
+func (r *fileReport) logln(a ...any) {
+	fmt.Fprintln(&r.log, a...)
+}
+
+func (r *fileReport) fail(err error) *fileReport {
+	r.err = err
+	return r
+}
+
+func (r *fileReport) flush() {
+	fmt.Fprint(statusOut(), r.log.String())
+	fmt.Fprint(os.Stdout, r.diff)
+}
+
+// saveFile writes modified back to absPath, or records a unified diff against
+// original on the report when running with --dry-run. A nil original means
+// the file is new.
+func saveFile(r *fileReport, absPath string, original, modified []byte) error {
+	if dryRun {
+		name := diffPath(absPath)
+		from := "a/" + name
+		if original == nil {
+			from = "/dev/null"
+		}
+		r.diff = fileops.UnifiedDiff(from, "b/"+name, original, modified, 3)
+		return nil
+	}
+
+	if err := fileops.WriteFile(absPath, modified); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	r.written = true
+	return nil
+}
+
+func diffPath(absPath string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, absPath); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(absPath), "/")
+}
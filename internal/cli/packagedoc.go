@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/llm"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+)
+
+// packageDoc is bound to --package-doc on the annotate command.
+var packageDoc bool
+
+// packageDirs returns the directories holding the Go packages that
+// annotating target touches.
+func packageDirs(target string, isDir bool) ([]string, error) {
+	if !isDir {
+		if filepath.Ext(target) != ".go" {
+			return nil, nil
+		}
+		return []string{filepath.Dir(target)}, nil
+	}
+
+	files, err := fileops.ScanDirectory(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory: %w", err)
+	}
+	seen := map[string]bool{}
+	var dirs []string
+	for _, f := range files {
+		dir := filepath.Dir(f.Path)
+		if f.Language == "go" && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// writePackageDocs adds a package comment, in doc.go, to each Go package in
+// dirs that has none.
+func (a *annotator) writePackageDocs(dirs []string) error {
+	failed := 0
+	for _, dir := range dirs {
+		report := a.writePackageDoc(dir)
+		report.flush()
+		if report.err != nil {
+			statusf("Error: %v\n", report.err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to document %d packages", failed)
+	}
+	return nil
+}
+
+func (a *annotator) writePackageDoc(dir string) *fileReport {
+	r := &fileReport{}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return r.fail(err)
+	}
+
+	target := llm.PackageTarget{}
+	var declarations []string
+	var docPkg *parser.GoPackage
+	var docSource []byte
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		source, err := fileops.ReadFile(path)
+		if err != nil {
+			return r.fail(fmt.Errorf("failed to read file: %w", err))
+		}
+		p, err := parser.NewParser(path)
+		if err != nil {
+			return r.fail(err)
+		}
+		pkg, err := p.Package(source)
+		if err != nil {
+			return r.fail(fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err))
+		}
+		if pkg == nil {
+			continue
+		}
+		if pkg.DocComment != "" {
+			// go doc takes the package comment from any file.
+			return r
+		}
+		if filepath.Base(path) == "doc.go" {
+			docPkg, docSource = pkg, source
+		}
+		target.Name = pkg.Name
+		target.Files = append(target.Files, filepath.Base(path))
+
+		blocks, err := p.Parse(source)
+		if err != nil {
+			return r.fail(fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err))
+		}
+		for _, block := range parser.SelectBlocks(blocks, parser.NestingTop) {
+			if block.Exported {
+				line, _, _ := strings.Cut(block.Code, "\n")
+				declarations = append(declarations, strings.TrimSuffix(strings.TrimSpace(line), "{"))
+			}
+		}
+	}
+	if target.Name == "" {
+		return r
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return r.fail(err)
+	}
+	if target.Name == "main" {
+		target.Command = true
+		target.Name = filepath.Base(absDir)
+	}
+	target.Declarations = strings.Join(declarations, "\n")
+
+	r.logf("Writing package comment for %s...\n", target.Name)
+	resp, err := a.client.Complete(context.Background(), &llm.CompletionRequest{
+		Messages:  llm.BuildPackagePrompt(target),
+		MaxTokens: 256,
+	})
+	if err != nil {
+		return r.fail(fmt.Errorf("failed to generate package comment: %w", err))
+	}
+	comment := llm.FormatPackageDoc(resp.Content, target)
+
+	path := filepath.Join(absDir, "doc.go")
+	var original, modified []byte
+	if docPkg != nil {
+		original = docSource
+		modified = fileops.InsertComment(docSource, docPkg.Line, comment, "go")
+	} else {
+		pkgName := target.Name
+		if target.Command {
+			pkgName = "main"
+		}
+		modified = []byte(comment + "\npackage " + pkgName + "\n")
+	}
+	if err := saveFile(r, path, original, modified); err != nil {
+		return r.fail(err)
+	}
+	r.logf("✓ Added package comment to %s\n", diffPath(path))
+	return r
+}
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/cloudboy-jh/annotr/internal/config"
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/llm"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+// refreshCheck is bound to refresh --check.
+var refreshCheck bool
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh [file or directory]",
+	Short: "Find and rewrite comments that no longer match their code",
+	Long: `Ask the configured model whether each existing doc comment still describes
+its code, and rewrite the ones that don't.
+
+Examples:
+  annotr refresh file.go          # Rewrite outdated comments in place
+  annotr refresh --check ./src    # Only report them; exit non-zero if any are found
+  annotr refresh --diff ./src     # Preview the rewrites as a unified diff`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRefresh,
+}
+
+func init() {
+	rootCmd.AddCommand(refreshCmd)
+	refreshCmd.Flags().BoolVar(&refreshCheck, "check", false, "report outdated comments without rewriting them")
+	refreshCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print a unified diff instead of writing files")
+	refreshCmd.Flags().BoolVar(&dryRun, "diff", false, "alias for --dry-run")
+	refreshCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "process every file without prompting")
+	refreshCmd.Flags().BoolVar(&assumeYes, "all", false, "alias for --yes")
+	refreshCmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "number of concurrent requests (default depends on provider)")
+	refreshCmd.Flags().BoolVar(&provenance, "provenance", false, "mark rewritten comments with the model and a hash of the code")
+	refreshCmd.Flags().StringVar(&nesting, "nesting", "", "which nested blocks to check: top, members or all (default members)")
+}
+
+func runRefresh(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg == nil {
+		fmt.Println("No configuration found. Run 'annotr init' first.")
+		return nil
+	}
+
+	policy, err := nestingPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	if err := llm.ValidateDocFormats(cfg.DocFormats); err != nil {
+		return err
+	}
+	if err := validateSQL(cfg, cfg.SQLCommentOn); err != nil {
+		return err
+	}
+
+	workers := jobs
+	if workers == 0 {
+		workers = llm.DefaultConcurrency(cfg.DefaultProvider)
+	}
+	a := &annotator{
+		cfg:        cfg,
+		client:     newClient(cfg, workers),
+		provenance: provenance || cfg.Provenance,
+		nesting:    policy,
+		commentOn:  cfg.SQLCommentOn,
+	}
+
+	target := args[0]
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", target, err)
+	}
+
+	var stats batchStats
+	if info.IsDir() {
+		files, err := fileops.ScanDirectory(target)
+		if err != nil {
+			return fmt.Errorf("failed to scan directory: %w", err)
+		}
+		if len(files) == 0 {
+			statusln("No supported files found in directory.")
+			return nil
+		}
+		stats = runBatch(files, "Refresh", workers, a.refreshFile)
+		statusf("Done! Checked %d of %d files (%s).\n", stats.processed, len(files), stats.summary())
+	} else {
+		report := a.refreshFile(target)
+		report.flush()
+		if report.err != nil {
+			return report.err
+		}
+		stats.findings = report.findings
+	}
+
+	if err := stats.err(); err != nil {
+		return err
+	}
+	if refreshCheck && stats.findings > 0 {
+		return fmt.Errorf("found %d outdated comments", stats.findings)
+	}
+	return nil
+}
+
+type verdict struct {
+	llm.RefreshVerdict
+	err error
+}
+
+func (a *annotator) refreshFile(path string) *fileReport {
+	r := &fileReport{}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	if !parser.IsSupportedFile(absPath) {
+		return r.fail(fmt.Errorf("unsupported file type: %s", filepath.Ext(path)))
+	}
+
+	r.logf("Checking %s...\n", filepath.Base(path))
+
+	source, err := fileops.ReadFile(absPath)
+	if err != nil {
+		return r.fail(fmt.Errorf("failed to read file: %w", err))
+	}
+
+	p, err := parser.NewParser(absPath)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	blocks, err := p.Parse(source)
+	if err != nil {
+		return r.fail(fmt.Errorf("failed to parse file: %w", err))
+	}
+	blocks = parser.SelectBlocks(blocks, a.nesting)
+
+	verdicts := make([]*verdict, len(blocks))
+	var wg sync.WaitGroup
+	for i, block := range blocks {
+		if block.DocComment == "" {
+			continue
+		}
+
+		verdicts[i] = &verdict{}
+		wg.Add(1)
+		go func(block parser.CodeBlock, out *verdict) {
+			defer wg.Done()
+			out.RefreshVerdict, out.err = a.checkComment(p, absPath, block)
+		}(block, verdicts[i])
+	}
+	wg.Wait()
+
+	checked := 0
+	for i, v := range verdicts {
+		if v == nil {
+			continue
+		}
+		checked++
+		block := blocks[i]
+		if v.err != nil {
+			r.logf("Warning: failed to check comment for %s: %v\n", block.Name, v.err)
+			continue
+		}
+		if v.Accurate {
+			continue
+		}
+		r.findings++
+		r.logf("\n%s:%d  %s\n", diffPath(absPath), block.AnchorLine+1, block.Name)
+		r.logf("  Old:      %s\n", indentContinuation(llm.StripDelimiters(block.DocComment)))
+		r.logf("  Reason:   %s\n", v.Reason)
+		r.logf("  Proposed: %s\n", indentContinuation(strings.TrimSpace(v.Comment)))
+	}
+	if r.findings > 0 {
+		r.logln()
+	}
+
+	if refreshCheck || r.findings == 0 {
+		r.logf("✓ Checked %d comments, %d outdated\n", checked, r.findings)
+		return r
+	}
+
+	// Rewrite bottom-up so earlier line numbers stay valid.
+	modifiedSource := source
+	for i := len(blocks) - 1; i >= 0; i-- {
+		v := verdicts[i]
+		if v == nil || v.err != nil || v.Accurate {
+			continue
+		}
+		comment := v.Comment
+		if _, marked := llm.ParseProvenance(blocks[i].DocComment); marked || a.provenance {
+			comment = llm.WithMarker(comment, llm.Provenance{
+				Model: a.cfg.DefaultModel,
+				Hash:  llm.CodeHash(blocks[i].Body()),
+			})
+		}
+		result := &generated{
+			comment: a.formatComment(comment, blocks[i], p.BlockLanguage(blocks[i])),
+			replace: true,
+		}
+		modifiedSource = a.placeComment(modifiedSource, blocks[i], result, p.BlockLanguage(blocks[i]))
+	}
+
+	if err := saveFile(r, absPath, source, modifiedSource); err != nil {
+		return r.fail(err)
+	}
+
+	r.logf("✓ Rewrote %d of %d comments\n", r.findings, checked)
+	return r
+}
+
+func (a *annotator) checkComment(p *parser.Parser, absPath string, block parser.CodeBlock) (llm.RefreshVerdict, error) {
+	messages := llm.BuildRefreshPrompt(llm.RefreshTarget{
+		Language: p.BlockLanguage(block),
+		Filename: filepath.Base(absPath),
+		Code:     block.Body(),
+		Comment:  llm.StripDelimiters(block.DocComment),
+	})
+	resp, err := a.client.Complete(context.Background(), &llm.CompletionRequest{
+		Messages:  messages,
+		MaxTokens: 512,
+	})
+	if err != nil {
+		return llm.RefreshVerdict{}, err
+	}
+	return llm.ParseRefreshResponse(resp.Content)
+}
+
+func indentContinuation(text string) string {
+	return strings.ReplaceAll(text, "\n", "\n            ")
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cloudboy-jh/annotr/internal/config"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+	"github.com/cloudboy-jh/annotr/internal/ui"
+)
+
+// review is bound to --review on the annotate command.
+var review bool
+
+// loadReview returns the saved review of absPath keyed by anchor line, or
+// nil if there is none or the file has changed since it was saved.
+func loadReview(absPath string, source []byte) (map[uint32]config.ReviewEntry, error) {
+	state, err := config.LoadReviewState(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load review state: %w", err)
+	}
+	if state == nil || state.SourceHash != sourceHash(source) {
+		return nil, nil
+	}
+
+	saved := make(map[uint32]config.ReviewEntry, len(state.Items))
+	for _, entry := range state.Items {
+		saved[entry.Line] = entry
+	}
+	return saved, nil
+}
+
+// reviewComments shows the generated comments for the user to accept,
+// reject, edit or regenerate. Rejected comments are dropped from results.
+// It returns false if the user quit before finishing, in which case the
+// review is saved so the next run picks up where it left off.
+func (a *annotator) reviewComments(absPath string, source []byte, blocks []parser.CodeBlock, results []*generated, saved map[uint32]config.ReviewEntry, regenerate func(i int) (string, error)) (bool, error) {
+	var items []ui.ReviewItem
+	var indices []int
+	for i, result := range results {
+		if result == nil || result.err != nil {
+			continue
+		}
+		item := ui.ReviewItem{
+			Name:    blocks[i].Name,
+			Line:    int(blocks[i].AnchorLine) + 1,
+			Code:    blocks[i].Code,
+			Comment: result.comment,
+			Status:  ui.ReviewPending,
+		}
+		if entry, ok := saved[blocks[i].AnchorLine]; ok {
+			item.Status = entry.Status
+		}
+		items = append(items, item)
+		indices = append(indices, i)
+	}
+	if len(items) == 0 {
+		return true, nil
+	}
+
+	model := ui.NewReviewModel(diffPath(absPath), items, func(n int) (string, error) {
+		return regenerate(indices[n])
+	})
+	final, err := tea.NewProgram(model, tea.WithAltScreen()).Run()
+	if err != nil {
+		return false, fmt.Errorf("error running review: %w", err)
+	}
+	m := final.(ui.ReviewModel)
+	items = m.Items()
+
+	if !m.Finished() {
+		state := &config.ReviewState{Path: absPath, SourceHash: sourceHash(source)}
+		for n, item := range items {
+			state.Items = append(state.Items, config.ReviewEntry{
+				Line:    blocks[indices[n]].AnchorLine,
+				Name:    item.Name,
+				Comment: item.Comment,
+				Status:  item.Status,
+			})
+		}
+		if err := state.Save(); err != nil {
+			return false, fmt.Errorf("failed to save review state: %w", err)
+		}
+		return false, nil
+	}
+
+	for n, item := range items {
+		if item.Status == ui.ReviewAccepted {
+			results[indices[n]].comment = item.Comment
+		} else {
+			results[indices[n]] = nil
+		}
+	}
+	if err := config.DeleteReviewState(absPath); err != nil {
+		return false, fmt.Errorf("failed to remove review state: %w", err)
+	}
+	return true, nil
+}
+
+func sourceHash(source []byte) string {
+	sum := sha256.Sum256(source)
+	return hex.EncodeToString(sum[:])
+}
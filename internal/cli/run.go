@@ -1,15 +1,17 @@
 package cli
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/cloudboy-jh/annotr/internal/config"
 	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/git"
 	"github.com/cloudboy-jh/annotr/internal/llm"
 	"github.com/cloudboy-jh/annotr/internal/parser"
 	"github.com/spf13/cobra"
@@ -18,12 +20,74 @@ import (
 func init() {
 	rootCmd.Args = cobra.MaximumNArgs(1)
 	rootCmd.RunE = runAnnotate
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print a unified diff instead of writing files")
+	rootCmd.Flags().BoolVar(&dryRun, "diff", false, "alias for --dry-run")
+	rootCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "process every file without prompting")
+	rootCmd.Flags().BoolVar(&assumeYes, "all", false, "alias for --yes")
+	rootCmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "number of concurrent requests (default depends on provider)")
+	rootCmd.Flags().BoolVar(&provenance, "provenance", false, "mark generated comments with the model and a hash of the code")
+	rootCmd.Flags().BoolVar(&regenerateStale, "regenerate-stale", false, "regenerate generated comments whose code has changed")
+	rootCmd.Flags().BoolVar(&review, "review", false, "review each generated comment before anything is written")
+	rootCmd.Flags().StringVar(&nesting, "nesting", "", "which nested blocks to comment: top, members or all (default members)")
+	rootCmd.Flags().BoolVar(&packageDoc, "package-doc", false, "add a package comment in doc.go to Go packages without one")
+	rootCmd.Flags().BoolVar(&inline, "inline", false, "also comment complex statements inside function bodies")
+	rootCmd.Flags().IntVar(&inlineDensity, "inline-density", 0, "lines of a function per inline comment (default 15)")
+	rootCmd.Flags().BoolVar(&commentOn, "comment-on", false, "write comments on SQL tables, views and routines as COMMENT ON statements")
+	addScopeFlags(rootCmd)
+}
+
+// Bound to the provenance flags on the annotate command.
+var (
+	provenance      bool
+	regenerateStale bool
+)
+
+// Bound to the inline comment flags on the annotate command.
+var (
+	inline        bool
+	inlineDensity int
+)
+
+// commentOn is bound to --comment-on on the annotate command.
+var commentOn bool
+
+// nesting is bound to --nesting on the annotate, refresh and coverage
+// commands.
+var nesting string
+
+// nestingPolicy returns the --nesting policy, falling back to the config and
+// then to commenting members but not local functions. cfg may be nil.
+func nestingPolicy(cfg *config.Config) (string, error) {
+	policy := nesting
+	if policy == "" && cfg != nil {
+		policy = cfg.Nesting
+	}
+	if policy == "" {
+		return parser.NestingMembers, nil
+	}
+	if err := parser.ValidateNesting(policy); err != nil {
+		return "", err
+	}
+	return policy, nil
+}
+
+// validateSQL checks the SQL settings in cfg. MySQL has no COMMENT ON
+// statement, so it cannot be combined with one.
+func validateSQL(cfg *config.Config, commentOn bool) error {
+	if err := parser.ValidateSQLDialect(cfg.SQLDialect); err != nil {
+		return err
+	}
+	if commentOn && cfg.SQLDialect == "mysql" {
+		return fmt.Errorf("COMMENT ON statements are not supported by MySQL")
+	}
+	return nil
 }
 
 func runAnnotate(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
+	if len(args) == 0 && !scoped() {
 		return cmd.Help()
 	}
+	cmd.SilenceUsage = true
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -34,153 +98,526 @@ func runAnnotate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	target := args[0]
+	if review && !isInteractive() {
+		return fmt.Errorf("--review needs an interactive terminal")
+	}
+
+	policy, err := nestingPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	if err := llm.ValidateDocFormats(cfg.DocFormats); err != nil {
+		return err
+	}
+	if err := validateSQL(cfg, commentOn || cfg.SQLCommentOn); err != nil {
+		return err
+	}
+
+	workers := jobs
+	if workers == 0 {
+		workers = llm.DefaultConcurrency(cfg.DefaultProvider)
+	}
+
+	target := "."
+	if len(args) > 0 {
+		target = args[0]
+	}
 	info, err := os.Stat(target)
 	if err != nil {
 		return fmt.Errorf("failed to access %s: %w", target, err)
 	}
 
+	dir := target
+	if !info.IsDir() {
+		dir = filepath.Dir(target)
+	}
+	changes, err := loadChanges(dir)
+	if err != nil {
+		return err
+	}
+
+	a := &annotator{
+		cfg:        cfg,
+		client:     newClient(cfg, workers),
+		changes:    changes,
+		provenance: provenance || cfg.Provenance,
+		review:     review,
+		nesting:    policy,
+		inline:     inline || cfg.Inline,
+		commentOn:  commentOn || cfg.SQLCommentOn,
+	}
+	if a.inlineDensity = inlineDensity; a.inlineDensity == 0 {
+		a.inlineDensity = cfg.InlineDensity
+	}
+
 	if info.IsDir() {
-		return processDirectory(cfg, target)
+		err = a.processDirectory(target, workers)
+	} else {
+		report := a.processFile(target)
+		report.flush()
+		err = report.err
+	}
+	if err != nil || !packageDoc {
+		return err
+	}
+
+	dirs, err := packageDirs(target, info.IsDir())
+	if err != nil {
+		return err
 	}
-	return processFile(cfg, target)
+	return a.writePackageDocs(dirs)
+}
+
+func newClient(cfg *config.Config, workers int) llm.Client {
+	apiKey := cfg.APIKeys[cfg.DefaultProvider]
+	return llm.NewLimitedClient(llm.NewClient(cfg.DefaultProvider, apiKey, cfg.DefaultModel), workers)
 }
 
-func processFile(cfg *config.Config, path string) error {
+// annotator holds everything shared by the files of one annotate run.
+type annotator struct {
+	cfg    *config.Config
+	client llm.Client
+	// changes limits annotation to blocks touched by git changes; nil means
+	// every block is a candidate.
+	changes git.Changes
+	// strict turns a failed comment generation into a failure of the whole
+	// file instead of a warning.
+	strict bool
+	// provenance appends a marker to every generated comment.
+	provenance bool
+	// review shows the generated comments for approval before writing.
+	review bool
+	// nesting is the policy choosing which nested blocks are commented.
+	nesting string
+	// inline adds comments above complex statements in function bodies, at
+	// most one per inlineDensity lines of a function.
+	inline        bool
+	inlineDensity int
+	// commentOn writes comments on SQL tables, views and routines as
+	// COMMENT ON statements below them.
+	commentOn bool
+}
+
+type generated struct {
+	comment string
+	err     error
+	// replace is set when the comment supersedes a stale generated one.
+	replace bool
+}
+
+func (a *annotator) processFile(path string) *fileReport {
+	r := &fileReport{}
+
 	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return err
+		return r.fail(err)
 	}
 
 	if !parser.IsSupportedFile(absPath) {
-		return fmt.Errorf("unsupported file type: %s", filepath.Ext(path))
+		return r.fail(fmt.Errorf("unsupported file type: %s", filepath.Ext(path)))
 	}
 
-	fmt.Printf("Processing %s...\n", filepath.Base(path))
+	r.logf("Processing %s...\n", filepath.Base(path))
+
+	if a.changes != nil && !a.changes.Has(absPath) {
+		r.logf("No %s changes.\n", scopeDescription())
+		return r
+	}
 
 	source, err := fileops.ReadFile(absPath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return r.fail(fmt.Errorf("failed to read file: %w", err))
 	}
 
 	p, err := parser.NewParser(absPath)
 	if err != nil {
-		return err
+		return r.fail(err)
 	}
 
-	blocks, err := p.Parse(source)
+	all, err := p.Parse(source)
 	if err != nil {
-		return fmt.Errorf("failed to parse file: %w", err)
+		return r.fail(fmt.Errorf("failed to parse file: %w", err))
+	}
+	blocks := parser.SelectBlocks(all, a.nesting)
+	if a.inline {
+		statements, err := p.ParseInline(source, all)
+		if err != nil {
+			return r.fail(fmt.Errorf("failed to parse file: %w", err))
+		}
+		blocks = withInline(blocks, parser.LimitInline(statements, a.inlineDensity))
 	}
 
 	if len(blocks) == 0 {
-		fmt.Println("No commentable code blocks found.")
-		return nil
+		r.logln("No commentable code blocks found.")
+		return r
 	}
 
-	apiKey := cfg.APIKeys[cfg.DefaultProvider]
-	client := llm.NewClient(cfg.DefaultProvider, apiKey, cfg.DefaultModel)
-
-	commentCount := 0
-	modifiedSource := source
-
-	for i := len(blocks) - 1; i >= 0; i-- {
-		block := blocks[i]
+	var saved map[uint32]config.ReviewEntry
+	if a.review {
+		saved, err = loadReview(absPath, source)
+		if err != nil {
+			return r.fail(err)
+		}
+	}
 
-		if hasExistingComment(modifiedSource, block.StartLine) {
+	// Generate every comment concurrently; the shared client bounds how many
+	// requests are actually in flight.
+	results := make([]*generated, len(blocks))
+	var wg sync.WaitGroup
+	for i, block := range blocks {
+		if a.changes != nil && !a.changes.Touches(absPath, int(block.StartLine)+1, int(block.EndLine)+1) {
+			continue
+		}
+		stale := false
+		if block.DocComment != "" {
+			if !isStale(block) {
+				continue
+			}
+			if !regenerateStale {
+				r.logf("Warning: generated comment for %s (line %d) is stale, rerun with --regenerate-stale\n", block.Name, block.StartLine+1)
+				continue
+			}
+			stale = true
+		}
+		results[i] = &generated{replace: stale}
+		if entry, ok := saved[block.AnchorLine]; ok {
+			results[i].comment = entry.Comment
 			continue
 		}
+		wg.Add(1)
+		go func(block parser.CodeBlock, out *generated) {
+			defer wg.Done()
+			out.comment, out.err = a.generateComment(p, source, absPath, block, a.provenance || out.replace)
+		}(block, results[i])
+	}
+	wg.Wait()
 
-		ctx := parser.BuildContext(source, block, 5)
-		target := llm.CommentTarget{
-			Language:     p.Language(),
-			Filename:     filepath.Base(absPath),
-			Code:         block.Code,
-			Context:      ctx,
-			CommentStyle: cfg.CommentStyle,
+	failed := 0
+	for i, result := range results {
+		if result != nil && result.err != nil {
+			r.logf("Warning: failed to generate comment for %s: %v\n", blocks[i].Name, result.err)
+			failed++
 		}
+	}
+	if a.strict && failed > 0 {
+		return r.fail(fmt.Errorf("failed to generate %d comments", failed))
+	}
 
-		messages := llm.BuildCommentPrompt(target)
-		resp, err := client.Complete(context.Background(), &llm.CompletionRequest{
-			Messages:  messages,
-			MaxTokens: 256,
+	if a.review {
+		done, err := a.reviewComments(absPath, source, blocks, results, saved, func(i int) (string, error) {
+			return a.generateComment(p, source, absPath, blocks[i], a.provenance || results[i].replace)
 		})
 		if err != nil {
-			fmt.Printf("Warning: failed to generate comment for %s: %v\n", block.Name, err)
-			continue
+			return r.fail(err)
+		}
+		if !done {
+			r.logln("Review saved, run the same command again to resume.")
+			r.stop = true
+			return r
 		}
+	}
 
-		comment := llm.FormatComment(resp.Content, p.Language(), cfg.CommentStyle)
-		modifiedSource = fileops.InsertComment(modifiedSource, block.StartLine, comment, p.Language())
+	// Insert bottom-up so earlier line numbers stay valid.
+	commentCount := 0
+	modifiedSource := source
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if results[i] == nil || results[i].err != nil {
+			continue
+		}
+		modifiedSource = a.placeComment(modifiedSource, blocks[i], results[i], p.BlockLanguage(blocks[i]))
 		commentCount++
 	}
 
 	if commentCount > 0 {
-		if err := fileops.WriteFile(absPath, modifiedSource); err != nil {
-			return fmt.Errorf("failed to write file: %w", err)
+		if err := saveFile(r, absPath, source, modifiedSource); err != nil {
+			return r.fail(err)
 		}
 	}
 
-	fmt.Printf("✓ Added %d comments\n\n", commentCount)
-	fmt.Println("Enjoy your comments! ;)")
+	r.logf("✓ Added %d comments\n\n", commentCount)
+	r.logln("Enjoy your comments! ;)")
 
-	return nil
+	return r
 }
 
-func processDirectory(cfg *config.Config, dir string) error {
-	files, err := fileops.ScanDirectory(dir)
+// withInline merges inline statements into blocks in line order, leaving
+// out any on a line that already has a block.
+func withInline(blocks, statements []parser.CodeBlock) []parser.CodeBlock {
+	taken := map[uint32]bool{}
+	for _, block := range blocks {
+		taken[block.AnchorLine] = true
+	}
+	for _, s := range statements {
+		if !taken[s.AnchorLine] {
+			blocks = append(blocks, s)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].AnchorLine < blocks[j].AnchorLine
+	})
+	return blocks
+}
+
+// isStale reports whether block's doc comment was generated by annotr for
+// code that has since changed.
+func isStale(block parser.CodeBlock) bool {
+	prov, ok := llm.ParseProvenance(block.DocComment)
+	return ok && prov.Hash != llm.CodeHash(block.Body())
+}
+
+// placeComment inserts a generated comment above block, or inside it for
+// inner docs and Python docstrings, first removing the stale comment it
+// replaces.
+func (a *annotator) placeComment(source []byte, block parser.CodeBlock, result *generated, language string) []byte {
+	if a.bodyDocstring(block, language) {
+		return placeDocstring(source, block, result)
+	}
+	if a.commentsOn(block, language) {
+		return placeBelow(source, block, result)
+	}
+	line := block.AnchorLine
+	if block.InnerDoc {
+		line++
+	}
+	if result.replace {
+		source = fileops.RemoveLines(source, block.DocStartLine, block.DocEndLine)
+		if block.DocStartLine < line {
+			line = block.DocStartLine
+		}
+	}
+	return fileops.InsertComment(source, line, result.comment, language)
+}
+
+// bodyDocstring reports whether the comment for block is a Python docstring,
+// which belongs inside the body rather than above the def.
+func (a *annotator) bodyDocstring(block parser.CodeBlock, language string) bool {
+	if language != "python" || block.BodyStart == 0 {
+		return false
+	}
+	return a.cfg.CommentStyle == "docstring" || a.cfg.CommentStyle == "block"
+}
+
+func placeDocstring(source []byte, block parser.CodeBlock, result *generated) []byte {
+	if result.replace && block.DocStartLine > block.StartLine {
+		// Write the new docstring over the old one, at its indentation.
+		added := uint32(strings.Count(result.comment, "\n") + 1)
+		source = fileops.InsertComment(source, block.DocStartLine, result.comment, "python")
+		return fileops.RemoveLines(source, block.DocStartLine+added, block.DocEndLine+added)
+	}
+	// The body comes after any comment being replaced above the def, so
+	// insert first while BodyStart is still valid.
+	source = fileops.InsertDocstring(source, block.BodyStart, result.comment)
+	if result.replace {
+		source = fileops.RemoveLines(source, block.DocStartLine, block.DocEndLine)
+	}
+	return source
+}
+
+// placeBelow inserts COMMENT ON statements right after the statement they
+// document, first removing the comment they replace, above or below it.
+func placeBelow(source []byte, block parser.CodeBlock, result *generated) []byte {
+	line := block.EndLine
+	if result.replace {
+		source = fileops.RemoveLines(source, block.DocStartLine, block.DocEndLine)
+		if block.DocEndLine < block.StartLine {
+			line -= block.DocEndLine - block.DocStartLine + 1
+		}
+	}
+	return fileops.InsertBelow(source, line, result.comment)
+}
+
+func (a *annotator) generateComment(p *parser.Parser, source []byte, absPath string, block parser.CodeBlock, mark bool) (string, error) {
+	ctx := parser.BuildContext(source, block, 5)
+	language := p.BlockLanguage(block)
+	target := llm.CommentTarget{
+		Language:     language,
+		Filename:     filepath.Base(absPath),
+		Code:         block.Code,
+		Context:      ctx,
+		CommentStyle: a.cfg.CommentStyle,
+		Name:         block.Name,
+	}
+	switch {
+	case block.Inline:
+		target.Inline = block.Type
+	case block.Type == "script":
+		target.Script = &llm.ScriptInputs{Env: block.Signature.Env}
+		for _, arg := range block.Signature.Params {
+			target.Script.Args = append(target.Script.Args, arg.Name)
+		}
+	default:
+		target.Tags = a.docTags(block, language)
+		target.Format = a.docFormat(language)
+	}
+	if language == "sql" {
+		if target.Dialect = a.cfg.SQLDialect; target.Dialect == "" {
+			target.Dialect = parser.SQLDialect(source)
+		}
+		for _, column := range block.Signature.Columns {
+			target.Columns = append(target.Columns, column.Name)
+		}
+		if block.Signature.Returns {
+			target.Returns = block.Signature.ReturnType
+		}
+	}
+
+	maxTokens := 256
+	if target.Format != "" || target.Script != nil || len(target.Columns) > 0 {
+		// Room for the JSON and the examples, a script's usage or a
+		// table's columns.
+		maxTokens = 512
+	}
+	messages := llm.BuildCommentPrompt(target)
+	resp, err := a.client.Complete(context.Background(), &llm.CompletionRequest{
+		Messages:  messages,
+		MaxTokens: maxTokens,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to scan directory: %w", err)
+		return "", err
 	}
 
-	if len(files) == 0 {
-		fmt.Println("No supported files found in directory.")
-		return nil
+	comment := resp.Content
+	if mark {
+		comment = llm.WithMarker(comment, llm.Provenance{
+			Model: a.cfg.DefaultModel,
+			Hash:  llm.CodeHash(block.Body()),
+		})
 	}
+	return a.formatComment(comment, block, language), nil
+}
 
-	reader := bufio.NewReader(os.Stdin)
-	processedCount := 0
-	skippedCount := 0
+// formatComment wraps comment in the doc comment syntax for block. Go doc
+// comments are made to start with the block's name; inline comments are
+// always short line comments.
+func (a *annotator) formatComment(comment string, block parser.CodeBlock, language string) string {
+	if block.Inline {
+		return llm.FormatInlineComment(comment, language)
+	}
+	if language == "sql" {
+		return a.formatSQL(comment, block)
+	}
+	if language == "go" {
+		return llm.GoDocName(a.formatDoc(comment, block, language), block.Name)
+	}
+	return a.formatDoc(comment, block, language)
+}
 
-	for _, file := range files {
-		fmt.Printf("Process %s? (y/n): ", file.Name)
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(strings.ToLower(input))
+// formatSQL formats a comment on a SQL statement as -- comments, or as
+// COMMENT ON statements with --comment-on where the statement has one.
+func (a *annotator) formatSQL(comment string, block parser.CodeBlock) string {
+	var columns []string
+	for _, column := range block.Signature.Columns {
+		columns = append(columns, column.Name)
+	}
+	if !a.commentsOn(block, "sql") {
+		return llm.FormatSQLComment(comment, columns)
+	}
 
-		if input == "y" || input == "yes" {
-			if err := processFile(cfg, file.Path); err != nil {
-				fmt.Printf("Error processing %s: %v\n", file.Name, err)
-			} else {
-				processedCount++
-			}
-		} else {
-			fmt.Printf("Skipped %s\n", file.Name)
-			skippedCount++
+	object := llm.SQLObject{Kind: parser.CommentOnKind(block.Type), Name: block.Name, Columns: columns}
+	if block.Type == "function" || block.Type == "procedure" {
+		var types []string
+		for _, param := range block.Signature.Params {
+			types = append(types, param.Type)
 		}
-		fmt.Println()
+		object.Name += "(" + strings.Join(types, ", ") + ")"
 	}
+	return llm.FormatCommentOn(comment, object)
+}
 
-	fmt.Printf("Done! Commented %d of %d files.\n", processedCount, len(files))
-	fmt.Println("Enjoy your comments! ;)")
+// commentsOn reports whether the comment for block is written as COMMENT ON
+// statements below it rather than as a comment above.
+func (a *annotator) commentsOn(block parser.CodeBlock, language string) bool {
+	return a.commentOn && language == "sql" && parser.CommentOnKind(block.Type) != ""
+}
 
-	return nil
+func (a *annotator) formatDoc(comment string, block parser.CodeBlock, language string) string {
+	if block.InnerDoc {
+		return llm.FormatInnerDoc(comment, language)
+	}
+	if format := a.docFormat(language); format != "" {
+		return llm.FormatStructured(comment, language, format, *a.docTags(block, language))
+	}
+	if tags := a.docTags(block, language); tags != nil {
+		switch language {
+		case "c", "cpp":
+			return llm.FormatDoxygen(comment, *tags)
+		case "ruby":
+			return llm.FormatYARD(comment, *tags)
+		case "php":
+			return llm.FormatPHPDoc(comment, *tags)
+		case "csharp":
+			return llm.FormatXMLDoc(comment, *tags)
+		case "swift":
+			return llm.FormatSwiftMarkup(comment, *tags)
+		}
+		return llm.FormatJavadoc(comment, *tags)
+	}
+	return llm.FormatComment(comment, language, a.cfg.CommentStyle)
 }
 
-func hasExistingComment(source []byte, lineNum uint32) bool {
-	lines := strings.Split(string(source), "\n")
-	if lineNum == 0 {
-		return false
+// docFormat returns the structured docstring format configured for
+// language, or "" when comments are free text.
+func (a *annotator) docFormat(language string) string {
+	if a.cfg.CommentStyle != "docstring" {
+		return ""
 	}
-	if int(lineNum-1) >= len(lines) {
-		return false
+	return a.cfg.DocFormats[language]
+}
+
+// docTags returns the tags for a Javadoc, KDoc, Doxygen, YARD, PHPDoc, C#
+// XML doc or Swift markup comment or a structured docstring on block, or
+// nil when comments for it are not written in that form.
+func (a *annotator) docTags(block parser.CodeBlock, language string) *llm.DocTags {
+	if a.cfg.CommentStyle != "docstring" {
+		return nil
+	}
+	switch language {
+	case "java", "kotlin", "c", "cpp", "ruby", "php", "csharp", "swift":
+	default:
+		if a.docFormat(language) == "" {
+			return nil
+		}
+	}
+	tags := &llm.DocTags{
+		Returns:    block.Signature.Returns,
+		ReturnType: block.Signature.ReturnType,
+		Throws:     block.Signature.Throws,
+	}
+	for _, p := range block.Signature.Params {
+		tags.Params = append(tags.Params, llm.DocParam{Name: p.Name, Type: p.Type, Optional: p.Optional})
+	}
+	return tags
+}
+
+func (a *annotator) processDirectory(dir string, workers int) error {
+	files, err := fileops.ScanDirectory(dir)
+	if err != nil {
+		return fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	if a.changes != nil {
+		files = filterChangedFiles(files, a.changes)
+		if len(files) == 0 {
+			statusf("No %s changes in supported files.\n", scopeDescription())
+			return nil
+		}
+	}
+
+	if len(files) == 0 {
+		statusln("No supported files found in directory.")
+		return nil
+	}
+
+	// The review screen needs the terminal to itself, one file at a time.
+	if a.review {
+		workers = 1
+	}
+	stats := runBatch(files, "Process", workers, a.processFile)
+
+	statusf("Done! Commented %d of %d files (%s).\n", stats.processed, len(files), stats.summary())
+	if stats.failed == 0 {
+		statusln("Enjoy your comments! ;)")
 	}
 
-	prevLine := strings.TrimSpace(lines[lineNum-1])
-	return strings.HasPrefix(prevLine, "//") ||
-		strings.HasPrefix(prevLine, "#") ||
-		strings.HasPrefix(prevLine, "/*") ||
-		strings.HasPrefix(prevLine, "*/") ||
-		strings.HasPrefix(prevLine, "*") ||
-		strings.HasPrefix(prevLine, `"""`) ||
-		strings.HasPrefix(prevLine, "'''")
+	return stats.err()
 }
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/git"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+// Bound to the git scoping flags on the annotate command.
+var (
+	scopeStaged       bool
+	scopeUncommitted  bool
+	scopeChangedSince string
+)
+
+func addScopeFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&scopeStaged, "staged", false, "only annotate code changed in the git index")
+	cmd.Flags().BoolVar(&scopeUncommitted, "uncommitted", false, "only annotate code changed since HEAD, including untracked files")
+	cmd.Flags().StringVar(&scopeChangedSince, "changed-since", "", "only annotate code changed since the given git ref")
+	cmd.MarkFlagsMutuallyExclusive("staged", "uncommitted", "changed-since")
+}
+
+func scoped() bool {
+	return scopeStaged || scopeUncommitted || scopeChangedSince != ""
+}
+
+// loadChanges returns the git changes selected by the scoping flags, or nil
+// when annotr should consider every block.
+func loadChanges(dir string) (git.Changes, error) {
+	switch {
+	case scopeStaged:
+		return stagedChanges(dir)
+	case scopeUncommitted:
+		return git.Uncommitted(dir)
+	case scopeChangedSince != "":
+		return git.ChangedSince(dir, scopeChangedSince)
+	default:
+		return nil, nil
+	}
+}
+
+// stagedChanges returns the staged changes, leaving out files that also have
+// unstaged edits. Staged hunks are numbered against the index copy, so in a
+// working tree that has moved on they would point at the wrong blocks.
+func stagedChanges(dir string) (git.Changes, error) {
+	staged, err := git.Staged(dir)
+	if err != nil {
+		return nil, err
+	}
+	unstaged, err := git.Unstaged(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := staged.Paths()
+	sort.Strings(paths)
+	for _, path := range paths {
+		if !unstaged.Has(path) {
+			continue
+		}
+		if parser.IsSupportedFile(path) {
+			statusf("Skipping %s: it has unstaged changes\n", filepath.Base(path))
+		}
+		delete(staged, path)
+	}
+	return staged, nil
+}
+
+func filterChangedFiles(files []fileops.FileInfo, changes git.Changes) []fileops.FileInfo {
+	var changed []fileops.FileInfo
+	for _, file := range files {
+		if changes.Has(file.Path) {
+			changed = append(changed, file)
+		}
+	}
+	return changed
+}
+
+func scopeDescription() string {
+	switch {
+	case scopeStaged:
+		return "staged"
+	case scopeUncommitted:
+		return "uncommitted"
+	default:
+		return fmt.Sprintf("changed since %s", scopeChangedSince)
+	}
+}
@@ -12,6 +12,23 @@ type Config struct {
 	DefaultProvider string            `json:"defaultProvider"`
 	DefaultModel    string            `json:"defaultModel"`
 	CommentStyle    string            `json:"commentStyle"`
+	// Provenance marks generated comments so annotr can find them later.
+	Provenance bool `json:"provenance,omitempty"`
+	// Nesting picks which nested blocks are commented: top, members or all.
+	Nesting string `json:"nesting,omitempty"`
+	// DocFormats maps a language to the structured docstring format, such
+	// as google or tsdoc, used with the docstring comment style.
+	DocFormats map[string]string `json:"docFormats,omitempty"`
+	// Inline adds short comments above complex statements inside function
+	// bodies, at most one per InlineDensity lines of a function.
+	Inline        bool `json:"inline,omitempty"`
+	InlineDensity int  `json:"inlineDensity,omitempty"`
+	// SQLDialect is the dialect of SQL files, postgres or mysql; empty
+	// means it is detected per file.
+	SQLDialect string `json:"sqlDialect,omitempty"`
+	// SQLCommentOn writes comments on SQL tables, views and routines as
+	// COMMENT ON statements instead of -- comments.
+	SQLCommentOn bool `json:"sqlCommentOn,omitempty"`
 }
 
 func DefaultConfig() *Config {
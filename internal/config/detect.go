@@ -46,3 +46,29 @@ func IsOllamaRunning() bool {
 	defer resp.Body.Close()
 	return resp.StatusCode == http.StatusOK
 }
+
+// IsProviderReachable reports whether the provider's API answers at all. Any
+// HTTP response counts, since most endpoints reply to a bare request with an
+// error status.
+func IsProviderReachable(provider string) bool {
+	if provider == "ollama" {
+		return IsOllamaRunning()
+	}
+
+	manifest, _ := LoadModelsManifest()
+	if manifest == nil {
+		manifest = DefaultModelsManifest()
+	}
+	p, ok := manifest.Providers[provider]
+	if !ok || p.Endpoint == "" {
+		return false
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Head(p.Endpoint)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
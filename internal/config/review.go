@@ -0,0 +1,91 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ReviewState is a half-finished comment review of one file, saved so that
+// quitting the review screen doesn't throw away the generated comments or
+// the decisions already made.
+type ReviewState struct {
+	Path string `json:"path"`
+	// SourceHash is the hash of the file contents the review was made
+	// against; a state whose file has since changed is discarded.
+	SourceHash string        `json:"sourceHash"`
+	Items      []ReviewEntry `json:"items"`
+}
+
+type ReviewEntry struct {
+	Line    uint32 `json:"line"`
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+	Status  string `json:"status"`
+}
+
+func ReviewStatePath(file string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(file))
+	return filepath.Join(dir, "review", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// LoadReviewState returns the saved review of file, or nil if there is none.
+func LoadReviewState(file string) (*ReviewState, error) {
+	path, err := ReviewStatePath(file)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var state ReviewState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state.Path != file {
+		return nil, nil
+	}
+
+	return &state, nil
+}
+
+func (s *ReviewState) Save() error {
+	path, err := ReviewStatePath(s.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func DeleteReviewState(file string) error {
+	path, err := ReviewStatePath(file)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
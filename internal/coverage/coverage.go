@@ -0,0 +1,141 @@
+package coverage
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+)
+
+type Item struct {
+	Line       int    `json:"line"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Exported   bool   `json:"exported"`
+	Documented bool   `json:"documented"`
+}
+
+type File struct {
+	Path       string `json:"path"`
+	Language   string `json:"language"`
+	Documented int    `json:"documented"`
+	Total      int    `json:"total"`
+	Items      []Item `json:"items"`
+	Error      string `json:"error,omitempty"`
+}
+
+type Stat struct {
+	Key        string  `json:"key"`
+	Documented int     `json:"documented"`
+	Total      int     `json:"total"`
+	Percent    float64 `json:"percent"`
+}
+
+type Report struct {
+	Documented int     `json:"documented"`
+	Total      int     `json:"total"`
+	Percent    float64 `json:"percent"`
+	Files      []File  `json:"files"`
+	ByLanguage []Stat  `json:"byLanguage"`
+	ByType     []Stat  `json:"byType"`
+}
+
+// Analyze parses files and records which commentable blocks already carry a
+// doc comment. With exportedOnly, unexported blocks are left out entirely, as
+// are nested blocks the nesting policy would not comment.
+func Analyze(files []fileops.FileInfo, exportedOnly bool, nesting string) *Report {
+	report := &Report{}
+	languages := map[string]*Stat{}
+	types := map[string]*Stat{}
+
+	for _, info := range files {
+		file := analyzeFile(info, exportedOnly, nesting)
+		report.Files = append(report.Files, file)
+
+		for _, item := range file.Items {
+			for _, stat := range []*Stat{statFor(languages, file.Language), statFor(types, item.Type)} {
+				stat.Total++
+				if item.Documented {
+					stat.Documented++
+				}
+			}
+		}
+		report.Documented += file.Documented
+		report.Total += file.Total
+	}
+
+	report.Percent = percent(report.Documented, report.Total)
+	report.ByLanguage = sortedStats(languages)
+	report.ByType = sortedStats(types)
+	return report
+}
+
+func analyzeFile(info fileops.FileInfo, exportedOnly bool, nesting string) File {
+	file := File{Path: info.Path, Language: info.Language}
+
+	source, err := fileops.ReadFile(info.Path)
+	if err != nil {
+		file.Error = fmt.Sprintf("failed to read file: %v", err)
+		return file
+	}
+
+	p, err := parser.NewParser(info.Path)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+
+	blocks, err := p.Parse(source)
+	if err != nil {
+		file.Error = fmt.Sprintf("failed to parse file: %v", err)
+		return file
+	}
+	file.Language = p.Language()
+
+	for _, block := range parser.SelectBlocks(blocks, nesting) {
+		if exportedOnly && !block.Exported {
+			continue
+		}
+
+		item := Item{
+			Line:       int(block.StartLine) + 1,
+			Name:       block.Name,
+			Type:       block.Type,
+			Exported:   block.Exported,
+			Documented: block.DocComment != "",
+		}
+		file.Items = append(file.Items, item)
+		file.Total++
+		if item.Documented {
+			file.Documented++
+		}
+	}
+	return file
+}
+
+func statFor(stats map[string]*Stat, key string) *Stat {
+	if stats[key] == nil {
+		stats[key] = &Stat{Key: key}
+	}
+	return stats[key]
+}
+
+func sortedStats(stats map[string]*Stat) []Stat {
+	result := make([]Stat, 0, len(stats))
+	for _, stat := range stats {
+		stat.Percent = percent(stat.Documented, stat.Total)
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+func percent(documented, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(documented) * 100 / float64(total)
+}
@@ -0,0 +1,45 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudboy-jh/annotr/internal/fileops"
+	"github.com/cloudboy-jh/annotr/internal/parser"
+)
+
+func TestAnalyzeExportedOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.ts")
+	source := `/** Loads a user. */
+export function load(id: string) {
+  const parse = (raw: string) => raw;
+  function check() {}
+  return parse(id);
+}
+
+export const save = () => {
+  const inner = () => 1;
+};
+
+function internal() {}
+`
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Analyze([]fileops.FileInfo{{Path: path, Name: "api.ts"}}, true, parser.NestingAll)
+	if report.Total != 2 || report.Documented != 1 {
+		t.Errorf("Analyze() = %d of %d documented, want 1 of 2: %+v", report.Documented, report.Total, report.Files)
+	}
+	if report.Percent != 50 {
+		t.Errorf("Percent = %v, want 50", report.Percent)
+	}
+}
+
+func TestAnalyzeEmpty(t *testing.T) {
+	report := Analyze(nil, false, parser.NestingAll)
+	if report.Total != 0 || report.Percent != 100 {
+		t.Errorf("Analyze(nil) = %+v, want an empty report at 100%%", report)
+	}
+}
@@ -0,0 +1,196 @@
+package coverage
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats lists the output formats accepted by Write.
+var Formats = []string{"text", "json", "junit", "checkstyle"}
+
+func Write(w io.Writer, r *Report, format string) error {
+	switch format {
+	case "text":
+		return writeText(w, r)
+	case "json":
+		return writeJSON(w, r)
+	case "junit":
+		return writeJUnit(w, r)
+	case "checkstyle":
+		return writeCheckstyle(w, r)
+	default:
+		return fmt.Errorf("unknown format %q (expected one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+func writeText(w io.Writer, r *Report) error {
+	fmt.Fprintf(w, "Documentation coverage: %d/%d (%.1f%%)\n", r.Documented, r.Total, r.Percent)
+
+	fmt.Fprintln(w, "\nBy file:")
+	for _, f := range r.Files {
+		if f.Error != "" {
+			fmt.Fprintf(w, "  %s  error: %s\n", f.Path, f.Error)
+			continue
+		}
+		fmt.Fprintf(w, "  %s  %d/%d (%.1f%%)\n", f.Path, f.Documented, f.Total, percent(f.Documented, f.Total))
+	}
+
+	fmt.Fprintln(w, "\nBy language:")
+	for _, s := range r.ByLanguage {
+		fmt.Fprintf(w, "  %s  %d/%d (%.1f%%)\n", s.Key, s.Documented, s.Total, s.Percent)
+	}
+
+	fmt.Fprintln(w, "\nBy block type:")
+	for _, s := range r.ByType {
+		fmt.Fprintf(w, "  %s  %d/%d (%.1f%%)\n", s.Key, s.Documented, s.Total, s.Percent)
+	}
+
+	var missing []string
+	for _, f := range r.Files {
+		for _, item := range f.Items {
+			if !item.Documented {
+				missing = append(missing, fmt.Sprintf("  %s:%d  %s %s", f.Path, item.Line, item.Type, item.Name))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Fprintln(w, "\nUndocumented:")
+		fmt.Fprintln(w, strings.Join(missing, "\n"))
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+func writeJUnit(w io.Writer, r *Report) error {
+	suites := junitTestSuites{Name: "annotr coverage"}
+	for _, f := range r.Files {
+		suite := junitTestSuite{Name: f.Path}
+		if f.Error != "" {
+			suite.Tests, suite.Errors = 1, 1
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "parse",
+				Classname: f.Path,
+				Error:     &junitFailure{Message: f.Error, Type: "error"},
+			})
+		}
+		for _, item := range f.Items {
+			tc := junitTestCase{
+				Name:      fmt.Sprintf("%s %s (line %d)", item.Type, item.Name, item.Line),
+				Classname: f.Path,
+			}
+			if !item.Documented {
+				tc.Failure = &junitFailure{Message: missingMessage(item), Type: "undocumented"}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+			suite.Tests++
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return writeXML(w, suites)
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func writeCheckstyle(w io.Writer, r *Report) error {
+	report := checkstyleReport{Version: "4.3"}
+	for _, f := range r.Files {
+		file := checkstyleFile{Name: f.Path}
+		if f.Error != "" {
+			file.Errors = append(file.Errors, checkstyleError{
+				Severity: "error",
+				Message:  f.Error,
+				Source:   "annotr.coverage.parse",
+			})
+		}
+		for _, item := range f.Items {
+			if item.Documented {
+				continue
+			}
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     item.Line,
+				Severity: "warning",
+				Message:  missingMessage(item),
+				Source:   "annotr.coverage.undocumented",
+			})
+		}
+		report.Files = append(report.Files, file)
+	}
+	return writeXML(w, report)
+}
+
+func missingMessage(item Item) string {
+	if item.Name == "" {
+		return fmt.Sprintf("%s has no doc comment", item.Type)
+	}
+	return fmt.Sprintf("%s %s has no doc comment", item.Type, item.Name)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,73 @@
+package coverage
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func sampleReport() *Report {
+	return &Report{
+		Documented: 1,
+		Total:      2,
+		Percent:    50,
+		Files: []File{
+			{
+				Path:       "a.go",
+				Language:   "go",
+				Documented: 1,
+				Total:      2,
+				Items: []Item{
+					{Line: 3, Name: "A", Type: "function", Exported: true, Documented: true},
+					{Line: 7, Name: "B", Type: "function", Exported: true},
+				},
+			},
+			{Path: "broken.go", Error: "failed to parse file"},
+		},
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{"Documentation coverage: 1/2 (50.0%)"}},
+		{"junit", []string{`<testsuites name="annotr coverage" tests="3" failures="1" errors="1">`, `message="function B has no doc comment"`}},
+		{"checkstyle", []string{`<file name="a.go">`, `line="7"`, `source="annotr.coverage.parse"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, sampleReport(), tt.format); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, sampleReport(), "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if decoded["total"] != float64(2) {
+		t.Errorf("total = %v, want 2", decoded["total"])
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, sampleReport(), "html"); err == nil {
+		t.Error("Write accepted an unknown format")
+	}
+}
@@ -0,0 +1,234 @@
+package fileops
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffOpKind int
+
+const (
+	opEqual diffOpKind = iota
+	opDelete
+	opInsert
+)
+
+type diffOp struct {
+	kind diffOpKind
+	line string
+	// Positions of the op in the old and new files, 0-based. For inserts
+	// oldPos is where the line lands, for deletes newPos likewise.
+	oldPos int
+	newPos int
+}
+
+// UnifiedDiff renders a unified diff between oldContent and newContent with
+// the given number of context lines. It returns an empty string when the
+// contents are identical.
+func UnifiedDiff(oldName, newName string, oldContent, newContent []byte, context int) string {
+	a := splitLinesKeepEnds(string(oldContent))
+	b := splitLinesKeepEnds(string(newContent))
+
+	ops := diffLines(a, b)
+	hunks := groupHunks(ops, context)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n", oldName)
+	fmt.Fprintf(&out, "+++ %s\n", newName)
+	for _, h := range hunks {
+		writeHunk(&out, h)
+	}
+	return out.String()
+}
+
+func splitLinesKeepEnds(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script between a and b using Myers'
+// algorithm, after trimming the common prefix and suffix.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: opEqual, line: a[i], oldPos: i, newPos: i})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+	for i := 0; i < suffix; i++ {
+		ai := len(a) - suffix + i
+		bi := len(b) - suffix + i
+		ops = append(ops, diffOp{kind: opEqual, line: a[ai], oldPos: ai, newPos: bi})
+	}
+	return ops
+}
+
+func myers(a, b []string, aOff, bOff int) []diffOp {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	max := n + m
+	v := make([]int, 2*max+2)
+	offset := max + 1
+	// trace[d] holds v[k] for k in [-d, d] after round d.
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+			}
+		}
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+	}
+
+	var reversed []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, diffOp{kind: opEqual, line: a[x], oldPos: aOff + x, newPos: bOff + y})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, diffOp{kind: opInsert, line: b[y], oldPos: aOff + x, newPos: bOff + y})
+		} else {
+			x--
+			reversed = append(reversed, diffOp{kind: opDelete, line: a[x], oldPos: aOff + x, newPos: bOff + y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, diffOp{kind: opEqual, line: a[x], oldPos: aOff + x, newPos: bOff + y})
+	}
+
+	ops := make([]diffOp, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+	return ops
+}
+
+func groupHunks(ops []diffOp, context int) [][]diffOp {
+	var hunks [][]diffOp
+	i := 0
+	for i < len(ops) {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend the hunk while the next change is close enough that the
+		// context around both would overlap.
+		last := i
+		for j := i + 1; j < len(ops) && j-last-1 <= 2*context; j++ {
+			if ops[j].kind != opEqual {
+				last = j
+			}
+		}
+
+		stop := last + 1 + context
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+		hunks = append(hunks, ops[start:stop])
+		i = stop
+	}
+	return hunks
+}
+
+func writeHunk(out *strings.Builder, hunk []diffOp) {
+	oldStart, newStart := hunk[0].oldPos, hunk[0].newPos
+	oldCount, newCount := 0, 0
+	for _, op := range hunk {
+		switch op.kind {
+		case opEqual:
+			oldCount++
+			newCount++
+		case opDelete:
+			oldCount++
+		case opInsert:
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, op := range hunk {
+		switch op.kind {
+		case opEqual:
+			out.WriteString(" ")
+		case opDelete:
+			out.WriteString("-")
+		case opInsert:
+			out.WriteString("+")
+		}
+		out.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	// An empty range is reported against the line preceding it.
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
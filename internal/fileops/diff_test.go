@@ -0,0 +1,157 @@
+package fileops
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "identical",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "insert comment",
+			old:  "package x\n\nfunc A() {}\n",
+			new:  "package x\n\n// A does nothing.\nfunc A() {}\n",
+			want: `--- a/x.go
++++ b/x.go
+@@ -2,2 +2,3 @@
+ 
++// A does nothing.
+ func A() {}
+`,
+		},
+		{
+			name: "replace line",
+			old:  "1\n2\n3\n4\n5\n",
+			new:  "1\n2\nthree\n4\n5\n",
+			want: `--- a/x.go
++++ b/x.go
+@@ -2,3 +2,3 @@
+ 2
+-3
++three
+ 4
+`,
+		},
+		{
+			name: "into empty file",
+			old:  "",
+			new:  "x\n",
+			want: `--- a/x.go
++++ b/x.go
+@@ -0,0 +1 @@
++x
+`,
+		},
+		{
+			name: "no newline at end",
+			old:  "a\nb",
+			new:  "a\nc",
+			want: `--- a/x.go
++++ b/x.go
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("a/x.go", "b/x.go", []byte(tt.old), []byte(tt.new), 1)
+			if got != tt.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	var old, new []string
+	for i := 0; i < 20; i++ {
+		line := fmt.Sprintf("line %d\n", i)
+		old = append(old, line)
+		new = append(new, line)
+	}
+	new[2] = "changed\n"
+	new[17] = "changed\n"
+
+	got := UnifiedDiff("a", "b", []byte(strings.Join(old, "")), []byte(strings.Join(new, "")), 3)
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Errorf("got %d hunks, want 2:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,6 +1,6 @@") || !strings.Contains(got, "@@ -15,6 +15,6 @@") {
+		t.Errorf("unexpected hunk headers:\n%s", got)
+	}
+}
+
+// TestDiffLinesMinimal checks on random inputs that the edit script turns a
+// into b and is as short as the longest common subsequence allows.
+func TestDiffLinesMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a\n", "b\n", "c\n", "d\n"}
+	random := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = words[rng.Intn(len(words))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range ops {
+			switch op.kind {
+			case opEqual:
+				gotA = append(gotA, op.line)
+				gotB = append(gotB, op.line)
+			case opDelete:
+				gotA = append(gotA, op.line)
+				edits++
+			case opInsert:
+				gotB = append(gotB, op.line)
+				edits++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("diffLines(%q, %q) does not reproduce its inputs: %v", a, b, ops)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); edits != want {
+			t.Fatalf("diffLines(%q, %q) uses %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return dp[0][0]
+}
@@ -23,18 +23,26 @@ func ScanDirectory(dir string) ([]FileInfo, error) {
 		}
 
 		if info.IsDir() {
+			if path == dir {
+				return nil
+			}
 			name := info.Name()
-			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__" {
+			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__" || name == "target" || name == "dbt_packages" {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
 		if parser.IsSupportedFile(path) {
+			language := getLanguageFromExt(filepath.Ext(path))
+			if language == "" {
+				// A script without an extension, found by its shebang.
+				language = "bash"
+			}
 			files = append(files, FileInfo{
 				Path:     path,
 				Name:     info.Name(),
-				Language: getLanguageFromExt(filepath.Ext(path)),
+				Language: language,
 			})
 		}
 
@@ -50,10 +58,38 @@ func getLanguageFromExt(ext string) string {
 		return "go"
 	case ".py":
 		return "python"
-	case ".js":
+	case ".js", ".jsx", ".mjs", ".cjs":
 		return "javascript"
 	case ".ts", ".tsx":
 		return "typescript"
+	case ".rs":
+		return "rust"
+	case ".java":
+		return "java"
+	case ".kt":
+		return "kotlin"
+	case ".c":
+		return "c"
+	case ".h", ".cpp", ".cc", ".hpp":
+		return "cpp"
+	case ".rb":
+		return "ruby"
+	case ".php":
+		return "php"
+	case ".sh", ".bash", ".zsh":
+		return "bash"
+	case ".cs":
+		return "csharp"
+	case ".swift":
+		return "swift"
+	case ".sql":
+		return "sql"
+	case ".vue":
+		return "vue"
+	case ".svelte":
+		return "svelte"
+	case ".html", ".htm":
+		return "html"
 	default:
 		return ""
 	}
@@ -27,14 +27,16 @@ func WriteFile(path string, content []byte) error {
 func InsertComment(source []byte, lineNum uint32, comment string, language string) []byte {
 	lines := strings.Split(string(source), "\n")
 	
-	if int(lineNum) > len(lines) {
+	if int(lineNum) >= len(lines) {
 		return source
 	}
 
 	indent := getIndent(lines[lineNum])
 	commentLines := strings.Split(comment, "\n")
 	for i, cl := range commentLines {
-		commentLines[i] = indent + cl
+		if cl != "" {
+			commentLines[i] = indent + cl
+		}
 	}
 	formattedComment := strings.Join(commentLines, "\n")
 
@@ -46,6 +48,60 @@ func InsertComment(source []byte, lineNum uint32, comment string, language strin
 	return []byte(strings.Join(newLines, "\n"))
 }
 
+// InsertBelow inserts comment on new lines after line lineNum, at its
+// indentation.
+func InsertBelow(source []byte, lineNum uint32, comment string) []byte {
+	lines := strings.Split(string(source), "\n")
+	if int(lineNum) >= len(lines) {
+		return source
+	}
+
+	indent := getIndent(lines[lineNum])
+	commentLines := strings.Split(comment, "\n")
+	for i, cl := range commentLines {
+		if cl != "" {
+			commentLines[i] = indent + cl
+		}
+	}
+
+	newLines := make([]string, 0, len(lines)+len(commentLines))
+	newLines = append(newLines, lines[:lineNum+1]...)
+	newLines = append(newLines, commentLines...)
+	newLines = append(newLines, lines[lineNum+1:]...)
+	return []byte(strings.Join(newLines, "\n"))
+}
+
+// InsertDocstring inserts docstring as the first statement of the Python
+// body whose first statement starts at byte at. A body sharing a line with
+// its signature, as in def f(): return x, is moved onto a line of its own.
+func InsertDocstring(source []byte, at uint32, docstring string) []byte {
+	lineStart := strings.LastIndexByte(string(source[:at]), '\n') + 1
+	before := string(source[lineStart:at])
+	if strings.TrimSpace(before) == "" {
+		row := uint32(strings.Count(string(source[:lineStart]), "\n"))
+		return InsertComment(source, row, docstring, "python")
+	}
+
+	indent := getIndent(before)
+	if strings.Contains(indent, "\t") {
+		indent += "\t"
+	} else {
+		indent += "    "
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(string(source[:at]), " \t"))
+	for _, line := range strings.Split(docstring, "\n") {
+		b.WriteString("\n")
+		if line != "" {
+			b.WriteString(indent + line)
+		}
+	}
+	b.WriteString("\n" + indent)
+	b.Write(source[at:])
+	return []byte(b.String())
+}
+
 func getIndent(line string) string {
 	var indent strings.Builder
 	for _, ch := range line {
@@ -57,3 +113,19 @@ func getIndent(line string) string {
 	}
 	return indent.String()
 }
+
+// RemoveLines deletes lines start through end (0-based, inclusive).
+func RemoveLines(source []byte, start, end uint32) []byte {
+	lines := strings.Split(string(source), "\n")
+	if int(start) >= len(lines) || end < start {
+		return source
+	}
+	if int(end) >= len(lines) {
+		end = uint32(len(lines) - 1)
+	}
+
+	newLines := make([]string, 0, len(lines))
+	newLines = append(newLines, lines[:start]...)
+	newLines = append(newLines, lines[end+1:]...)
+	return []byte(strings.Join(newLines, "\n"))
+}
@@ -0,0 +1,109 @@
+package fileops
+
+import "testing"
+
+func TestInsertComment(t *testing.T) {
+	source := "package x\n\nfunc A() {\n\tif true {\n\t\treturn\n\t}\n}\n"
+	tests := []struct {
+		name    string
+		line    uint32
+		comment string
+		want    string
+	}{
+		{
+			name:    "top level",
+			line:    2,
+			comment: "// A does nothing.",
+			want:    "package x\n\n// A does nothing.\nfunc A() {\n\tif true {\n\t\treturn\n\t}\n}\n",
+		},
+		{
+			name:    "indented, several lines",
+			line:    3,
+			comment: "// Always taken.\n//\n// Really.",
+			want:    "package x\n\nfunc A() {\n\t// Always taken.\n\t//\n\t// Really.\n\tif true {\n\t\treturn\n\t}\n}\n",
+		},
+		{
+			name:    "blank lines stay unindented",
+			line:    4,
+			comment: "/*\n\nx\n*/",
+			want:    "package x\n\nfunc A() {\n\tif true {\n\t\t/*\n\n\t\tx\n\t\t*/\n\t\treturn\n\t}\n}\n",
+		},
+		{
+			name:    "just past the end",
+			line:    8,
+			comment: "// lost",
+			want:    source,
+		},
+		{
+			name:    "past the end",
+			line:    99,
+			comment: "// lost",
+			want:    source,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(InsertComment([]byte(source), tt.line, tt.comment, "go")); got != tt.want {
+				t.Errorf("InsertComment() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveLines(t *testing.T) {
+	source := []byte("a\nb\nc\nd\n")
+	tests := []struct {
+		start, end uint32
+		want       string
+	}{
+		{1, 2, "a\nd\n"},
+		{0, 0, "b\nc\nd\n"},
+		{3, 3, "a\nb\nc\n"},
+		{2, 1, "a\nb\nc\nd\n"},
+		{99, 99, "a\nb\nc\nd\n"},
+	}
+	for _, tt := range tests {
+		if got := string(RemoveLines(source, tt.start, tt.end)); got != tt.want {
+			t.Errorf("RemoveLines(%d, %d) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestInsertDocstring(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		at     int
+		want   string
+	}{
+		{
+			name:   "own line",
+			source: "def f():\n    return 1\n",
+			at:     13,
+			want:   "def f():\n    \"\"\"Return one.\"\"\"\n    return 1\n",
+		},
+		{
+			name:   "same line as def",
+			source: "def f(): return 1\n",
+			at:     9,
+			want:   "def f():\n    \"\"\"Return one.\"\"\"\n    return 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(InsertDocstring([]byte(tt.source), uint32(tt.at), `"""Return one."""`))
+			if got != tt.want {
+				t.Errorf("InsertDocstring() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertBelow(t *testing.T) {
+	source := "CREATE TABLE t (id int);\nSELECT 1;\n"
+	got := string(InsertBelow([]byte(source), 0, "COMMENT ON TABLE t IS 'x';"))
+	want := "CREATE TABLE t (id int);\nCOMMENT ON TABLE t IS 'x';\nSELECT 1;\n"
+	if got != want {
+		t.Errorf("InsertBelow() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LineRange is an inclusive, 1-based range of lines in the new version of a
+// file.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Changes maps absolute file paths to the line ranges that changed in them.
+type Changes map[string][]LineRange
+
+var wholeFile = LineRange{Start: 1, End: math.MaxInt}
+
+// Staged returns the changes in the index relative to HEAD.
+func Staged(dir string) (Changes, error) {
+	return diffChanges(dir, "--cached")
+}
+
+// Uncommitted returns every change in the working tree and index relative to
+// HEAD, plus untracked files.
+func Uncommitted(dir string) (Changes, error) {
+	changes, err := diffChanges(dir, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if err := addUntracked(dir, changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// ChangedSince returns the changes in the working tree relative to ref.
+func ChangedSince(dir, ref string) (Changes, error) {
+	if _, err := run(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown git ref %q", ref)
+	}
+	return diffChanges(dir, ref)
+}
+
+// Has reports whether path has any changes.
+func (c Changes) Has(path string) bool {
+	_, ok := c[canonical(path)]
+	return ok
+}
+
+// Touches reports whether any change in path overlaps the lines start to end
+// (1-based, inclusive).
+func (c Changes) Touches(path string, start, end int) bool {
+	for _, r := range c[canonical(path)] {
+		if r.Start <= end && start <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Paths returns the changed files.
+func (c Changes) Paths() []string {
+	paths := make([]string, 0, len(c))
+	for path := range c {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// RepoRoot returns the top-level directory of the repository containing dir.
+func RepoRoot(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("%s is not inside a git repository", dir)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+func diffChanges(dir string, args ...string) (Changes, error) {
+	root, err := RepoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fixed prefixes override diff.noprefix and diff.mnemonicPrefix, which
+	// would otherwise change how the +++ lines name files.
+	diffArgs := append([]string{"diff", "--no-color", "--no-ext-diff", "-U0", "--diff-filter=ACMR", "--src-prefix=a/", "--dst-prefix=b/"}, args...)
+	out, err := run(root, diffArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiff(root, out)
+}
+
+// parseDiff reads the new-file line ranges out of -U0 unified diff output,
+// resolving file names against root.
+func parseDiff(root string, out []byte) (Changes, error) {
+	changes := Changes{}
+	var current string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			name := diffName(strings.TrimPrefix(line, "+++ "))
+			if name == "/dev/null" {
+				current = ""
+				continue
+			}
+			current = canonical(filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/"))))
+			if _, ok := changes[current]; !ok {
+				changes[current] = nil
+			}
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			changes[current] = append(changes[current], hunkRange(start, count))
+		}
+	}
+	return changes, scanner.Err()
+}
+
+// diffName undoes git's decoration of a file name in a ---/+++ line: the tab
+// it appends to names containing spaces, and C-style quoting of names with
+// control characters or quotes.
+func diffName(name string) string {
+	name = strings.TrimSuffix(name, "\t")
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			return unquoted
+		}
+	}
+	return name
+}
+
+// hunkRange converts a hunk's new-file position to a line range. A pure
+// deletion has no new lines, so it is attributed to the lines either side.
+func hunkRange(start, count int) LineRange {
+	if count == 0 {
+		if start < 1 {
+			return LineRange{Start: 1, End: 1}
+		}
+		return LineRange{Start: start, End: start + 1}
+	}
+	return LineRange{Start: start, End: start + count - 1}
+}
+
+func addUntracked(dir string, changes Changes) error {
+	root, err := RepoRoot(dir)
+	if err != nil {
+		return err
+	}
+
+	out, err := run(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name == "" {
+			continue
+		}
+		changes[canonical(filepath.Join(root, filepath.FromSlash(name)))] = []LineRange{wholeFile}
+	}
+	return nil
+}
+
+// canonical resolves symlinks so paths from git and from the command line
+// compare equal.
+func canonical(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+func run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-c", "core.quotePath=false"}, args...)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return out, nil
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	root := t.TempDir()
+	at := func(name string) string { return canonical(filepath.Join(root, name)) }
+
+	tests := []struct {
+		name string
+		diff string
+		want Changes
+	}{
+		{
+			name: "modified",
+			diff: `diff --git a/main.go b/main.go
+index 422c2b7..95f3767 100644
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ func main() {
+@@ -10 +12 @@ func helper() {
+`,
+			want: Changes{at("main.go"): {{Start: 4, End: 5}, {Start: 12, End: 12}}},
+		},
+		{
+			name: "new file",
+			diff: `diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3e75765
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,7 @@
+`,
+			want: Changes{at("new.go"): {{Start: 1, End: 7}}},
+		},
+		{
+			name: "rename with edits",
+			diff: `diff --git a/old.go b/pkg/renamed.go
+similarity index 90%
+rename from old.go
+rename to pkg/renamed.go
+index 422c2b7..95f3767 100644
+--- a/old.go
++++ b/pkg/renamed.go
+@@ -2 +2 @@ package main
+`,
+			want: Changes{at("pkg/renamed.go"): {{Start: 2, End: 2}}},
+		},
+		{
+			name: "pure rename",
+			diff: `diff --git a/old.go b/renamed.go
+similarity index 100%
+rename from old.go
+rename to renamed.go
+`,
+			want: Changes{},
+		},
+		{
+			name: "deletion only",
+			diff: `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -5,2 +4,0 @@ func main() {
+`,
+			want: Changes{at("main.go"): {{Start: 4, End: 5}}},
+		},
+		{
+			name: "name with spaces",
+			diff: "diff --git a/my file.go b/my file.go\n--- a/my file.go\t\n+++ b/my file.go\t\n@@ -1 +1 @@\n",
+			want: Changes{at("my file.go"): {{Start: 1, End: 1}}},
+		},
+		{
+			name: "quoted name",
+			diff: "diff --git \"a/tab\\there.go\" \"b/tab\\there.go\"\n--- \"a/tab\\there.go\"\n+++ \"b/tab\\there.go\"\n@@ -1 +1 @@\n",
+			want: Changes{at("tab\there.go"): {{Start: 1, End: 1}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDiff(root, []byte(tt.diff))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	tests := []struct {
+		start, count int
+		want         LineRange
+	}{
+		{start: 3, count: 1, want: LineRange{Start: 3, End: 3}},
+		{start: 3, count: 4, want: LineRange{Start: 3, End: 6}},
+		{start: 3, count: 0, want: LineRange{Start: 3, End: 4}},
+		{start: 0, count: 0, want: LineRange{Start: 1, End: 1}},
+	}
+	for _, tt := range tests {
+		if got := hunkRange(tt.start, tt.count); got != tt.want {
+			t.Errorf("hunkRange(%d, %d) = %v, want %v", tt.start, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestStagedIgnoresPrefixConfig(t *testing.T) {
+	for _, config := range [][]string{
+		{"diff.mnemonicPrefix", "true"},
+		{"diff.noprefix", "true"},
+	} {
+		t.Run(config[0], func(t *testing.T) {
+			dir := newRepo(t)
+			gitCmd(t, dir, "config", config[0], config[1])
+			writeFile(t, dir, "x.go", "package x\n\nfunc A() {}\n")
+			gitCmd(t, dir, "add", "x.go")
+
+			changes, err := Staged(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "x.go")
+			if !changes.Has(path) {
+				t.Fatalf("Staged() = %v, want an entry for %s", changes, path)
+			}
+			if !changes.Touches(path, 3, 3) {
+				t.Errorf("Staged() does not cover line 3 of x.go: %v", changes)
+			}
+		})
+	}
+}
+
+func TestUncommittedIncludesUntracked(t *testing.T) {
+	dir := newRepo(t)
+	writeFile(t, dir, "new.go", "package x\n")
+
+	changes, err := Uncommitted(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changes.Touches(filepath.Join(dir, "new.go"), math.MaxInt-1, math.MaxInt-1) {
+		t.Errorf("Uncommitted() = %v, want the whole of new.go", changes)
+	}
+}
+
+// newRepo creates a repository with one commit, isolated from the user's
+// git configuration.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := canonical(t.TempDir())
+	gitCmd(t, dir, "init", "-q")
+	gitCmd(t, dir, "config", "user.email", "test@example.com")
+	gitCmd(t, dir, "config", "user.name", "test")
+	gitCmd(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+	return dir
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if _, err := run(dir, args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
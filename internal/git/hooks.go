@@ -0,0 +1,56 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HooksDir returns the directory git runs hooks from for the repository
+// containing dir, honouring core.hooksPath.
+func HooksDir(dir string) (string, error) {
+	root, err := RepoRoot(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if out, err := run(root, "config", "--get", "core.hooksPath"); err == nil {
+		hooksPath := strings.TrimSpace(string(out))
+		if hooksPath != "" {
+			if strings.HasPrefix(hooksPath, "~/") {
+				if home, err := os.UserHomeDir(); err == nil {
+					hooksPath = filepath.Join(home, hooksPath[2:])
+				}
+			}
+			if !filepath.IsAbs(hooksPath) {
+				hooksPath = filepath.Join(root, hooksPath)
+			}
+			return hooksPath, nil
+		}
+	}
+
+	out, err := run(root, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	hooksPath := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksPath) {
+		hooksPath = filepath.Join(root, hooksPath)
+	}
+	return hooksPath, nil
+}
+
+// Unstaged returns the changes in the working tree that are not yet in the
+// index.
+func Unstaged(dir string) (Changes, error) {
+	return diffChanges(dir)
+}
+
+// Add stages paths in the repository containing dir.
+func Add(dir string, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	_, err := run(dir, append([]string{"add", "--"}, paths...)...)
+	return err
+}
@@ -48,3 +48,41 @@ func NewClient(provider, apiKey, model string) Client {
 		return NewOllamaClient(model)
 	}
 }
+
+// DefaultConcurrency is how many requests annotr keeps in flight against a
+// provider when --jobs is not given. Local Ollama serialises generation, so
+// extra parallelism there only adds queueing.
+func DefaultConcurrency(provider string) int {
+	switch provider {
+	case "openai":
+		return 8
+	case "groq", "anthropic":
+		return 4
+	default:
+		return 2
+	}
+}
+
+type limitedClient struct {
+	Client
+	sem chan struct{}
+}
+
+// NewLimitedClient wraps c so that at most n Complete calls run at once,
+// however many goroutines share it.
+func NewLimitedClient(c Client, n int) Client {
+	if n < 1 {
+		n = 1
+	}
+	return &limitedClient{Client: c, sem: make(chan struct{}, n)}
+}
+
+func (c *limitedClient) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	select {
+	case c.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-c.sem }()
+	return c.Client.Complete(ctx, req)
+}
@@ -0,0 +1,385 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DocFormats lists the structured docstring formats each language can be
+// configured with. "rest" is accepted as another name for "sphinx".
+var DocFormats = map[string][]string{
+	"python":     {"google", "numpy", "sphinx"},
+	"javascript": {"jsdoc"},
+	"typescript": {"tsdoc", "jsdoc"},
+	"go":         {"godoc"},
+}
+
+// ValidateDocFormats checks a language to format mapping from the config.
+func ValidateDocFormats(formats map[string]string) error {
+	for language, format := range formats {
+		allowed, ok := DocFormats[language]
+		if !ok {
+			return fmt.Errorf("docstring formats are not supported for %s", language)
+		}
+		if format == "rest" {
+			format = "sphinx"
+		}
+		found := false
+		for _, f := range allowed {
+			found = found || f == format
+		}
+		if !found {
+			return fmt.Errorf("unknown docstring format %q for %s (want %s)", format, language, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// StructuredDoc is what the model is asked to return, as JSON, when a
+// docstring format is configured. annotr lays it out itself.
+type StructuredDoc struct {
+	Summary  string            `json:"summary"`
+	Params   map[string]string `json:"params,omitempty"`
+	Returns  string            `json:"returns,omitempty"`
+	Raises   map[string]string `json:"raises,omitempty"`
+	Examples []string          `json:"examples,omitempty"`
+}
+
+func buildStructuredPrompt(target CommentTarget) []Message {
+	systemPrompt := `You are a code documentation expert. Describe code blocks for their docstrings.
+Rules:
+- Be brief but informative
+- Focus on the "why" not the "what"
+- Respond with ONLY a JSON object, no prose and no code fences
+- Do not include comment delimiters or docstring markup`
+
+	tags := DocTags{}
+	if target.Tags != nil {
+		tags = *target.Tags
+	}
+
+	fields := []string{`"summary": what the code does, in 1-3 sentences`}
+	if target.Format == "godoc" {
+		fields[0] += fmt.Sprintf(", starting with %q and mentioning the parameters where useful", target.Name)
+	} else {
+		if len(tags.Params) > 0 {
+			var names []string
+			for _, p := range tags.Params {
+				names = append(names, p.Name)
+			}
+			fields = append(fields, `"params": an object with a short description for each of: `+strings.Join(names, ", "))
+		}
+		if tags.Returns {
+			fields = append(fields, `"returns": a short description of the return value`)
+		}
+		if len(tags.Throws) > 0 {
+			fields = append(fields, `"raises": an object saying when each of these is raised: `+strings.Join(tags.Throws, ", "))
+		}
+	}
+	fields = append(fields, `"examples": a list of short usage examples as code, or an empty list`)
+
+	userPrompt := fmt.Sprintf(`Language: %s
+File: %s
+
+Context:
+%s
+
+Target Code:
+%s
+
+Describe the target code as a JSON object with these keys:
+%s`,
+		target.Language,
+		target.Filename,
+		target.Context,
+		target.Code,
+		"- "+strings.Join(fields, "\n- "),
+	)
+
+	return []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+}
+
+// parseStructured reads the model's JSON answer. Anything that is not JSON,
+// such as a plain comment from refresh, becomes the summary. A provenance
+// marker is returned separately so it can go last.
+func parseStructured(content string) (StructuredDoc, string) {
+	var marker string
+	var rest []string
+	for _, line := range strings.Split(content, "\n") {
+		if markerPattern.MatchString(line) {
+			marker = strings.TrimSpace(line)
+			continue
+		}
+		rest = append(rest, line)
+	}
+	text := strings.TrimSpace(strings.Join(rest, "\n"))
+
+	var doc StructuredDoc
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start || json.Unmarshal([]byte(text[start:end+1]), &doc) != nil {
+		doc = StructuredDoc{Summary: text}
+	}
+	doc.Summary = strings.TrimSpace(doc.Summary)
+	return doc, marker
+}
+
+// FormatStructured lays out the model's JSON answer as a docstring in
+// format, complete with the comment syntax of language. Parameters, return
+// value and exceptions come from tags; the model only describes them.
+func FormatStructured(content, language, format string, tags DocTags) string {
+	doc, marker := parseStructured(content)
+
+	var lines []string
+	switch format {
+	case "numpy":
+		lines = numpyDoc(doc, tags)
+	case "sphinx", "rest":
+		lines = sphinxDoc(doc, tags)
+	case "jsdoc", "tsdoc":
+		lines = jsDoc(doc, tags, format == "tsdoc")
+	case "godoc":
+		lines = goDoc(doc)
+	default:
+		lines = googleDoc(doc, tags)
+	}
+	if marker != "" {
+		lines = section(lines, marker)
+	}
+
+	switch language {
+	case "python":
+		if len(lines) == 1 {
+			return `"""` + lines[0] + `"""`
+		}
+		return `"""` + strings.Join(lines, "\n") + "\n" + `"""`
+	case "go":
+		for i, line := range lines {
+			switch {
+			case line == "":
+				lines[i] = "//"
+			case strings.HasPrefix(line, "\t"):
+				lines[i] = "//" + line
+			default:
+				lines[i] = "// " + line
+			}
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return docBlock(lines)
+	}
+}
+
+// section appends a blank line and then body to lines, unless body is empty.
+func section(lines []string, body ...string) []string {
+	if len(body) == 0 {
+		return lines
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	return append(lines, body...)
+}
+
+func indent(prefix, text string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		lines = append(lines, strings.TrimRight(prefix+line, " \t"))
+	}
+	return lines
+}
+
+func summaryLines(doc StructuredDoc) []string {
+	if doc.Summary == "" {
+		return nil
+	}
+	return strings.Split(doc.Summary, "\n")
+}
+
+func googleDoc(doc StructuredDoc, tags DocTags) []string {
+	lines := summaryLines(doc)
+
+	if len(tags.Params) > 0 {
+		args := []string{"Args:"}
+		for _, p := range tags.Params {
+			var kind []string
+			if p.Type != "" {
+				kind = append(kind, p.Type)
+			}
+			if p.Optional {
+				kind = append(kind, "optional")
+			}
+			name := p.Name
+			if len(kind) > 0 {
+				name += " (" + strings.Join(kind, ", ") + ")"
+			}
+			args = append(args, indent("    ", name+": "+doc.Params[p.Name])...)
+		}
+		lines = section(lines, args...)
+	}
+	if tags.Returns {
+		desc := doc.Returns
+		if tags.ReturnType != "" {
+			desc = tags.ReturnType + ": " + desc
+		}
+		lines = section(lines, append([]string{"Returns:"}, indent("    ", desc)...)...)
+	}
+	if len(tags.Throws) > 0 {
+		raises := []string{"Raises:"}
+		for _, exc := range tags.Throws {
+			raises = append(raises, indent("    ", exc+": "+doc.Raises[exc])...)
+		}
+		lines = section(lines, raises...)
+	}
+	if examples := strings.Join(doc.Examples, "\n"); examples != "" {
+		lines = section(lines, append([]string{"Examples:"}, indent("    ", examples)...)...)
+	}
+	return lines
+}
+
+func numpyDoc(doc StructuredDoc, tags DocTags) []string {
+	lines := summaryLines(doc)
+	heading := func(title string) []string {
+		return []string{title, strings.Repeat("-", len(title))}
+	}
+	entry := func(head, desc string) []string {
+		if desc == "" {
+			return []string{head}
+		}
+		return append([]string{head}, indent("    ", desc)...)
+	}
+
+	if len(tags.Params) > 0 {
+		params := heading("Parameters")
+		for _, p := range tags.Params {
+			head := p.Name
+			if p.Type != "" {
+				head += " : " + p.Type
+			}
+			if p.Optional {
+				if p.Type == "" {
+					head += " :"
+				} else {
+					head += ","
+				}
+				head += " optional"
+			}
+			params = append(params, entry(head, doc.Params[p.Name])...)
+		}
+		lines = section(lines, params...)
+	}
+	if tags.Returns {
+		returns := heading("Returns")
+		if tags.ReturnType != "" {
+			returns = append(returns, entry(tags.ReturnType, doc.Returns)...)
+		} else if doc.Returns != "" {
+			returns = append(returns, strings.Split(doc.Returns, "\n")...)
+		}
+		lines = section(lines, returns...)
+	}
+	if len(tags.Throws) > 0 {
+		raises := heading("Raises")
+		for _, exc := range tags.Throws {
+			raises = append(raises, entry(exc, doc.Raises[exc])...)
+		}
+		lines = section(lines, raises...)
+	}
+	if examples := strings.Join(doc.Examples, "\n"); examples != "" {
+		lines = section(lines, append(heading("Examples"), strings.Split(examples, "\n")...)...)
+	}
+	return lines
+}
+
+func sphinxDoc(doc StructuredDoc, tags DocTags) []string {
+	lines := summaryLines(doc)
+
+	var fields []string
+	for _, p := range tags.Params {
+		fields = append(fields, strings.TrimRight(":param "+p.Name+": "+doc.Params[p.Name], " "))
+		if p.Type != "" {
+			fields = append(fields, ":type "+p.Name+": "+p.Type)
+		}
+	}
+	if tags.Returns {
+		fields = append(fields, strings.TrimRight(":returns: "+doc.Returns, " "))
+		if tags.ReturnType != "" {
+			fields = append(fields, ":rtype: "+tags.ReturnType)
+		}
+	}
+	for _, exc := range tags.Throws {
+		fields = append(fields, strings.TrimRight(":raises "+exc+": "+doc.Raises[exc], " "))
+	}
+	lines = section(lines, fields...)
+
+	if examples := strings.Join(doc.Examples, "\n"); examples != "" {
+		lines = section(lines, "Example::", "")
+		lines = append(lines, indent("    ", examples)...)
+	}
+	return lines
+}
+
+// jsDoc lays out JSDoc tags, or TSDoc ones with tsdoc, where types live in
+// the code rather than the comment.
+func jsDoc(doc StructuredDoc, tags DocTags, tsdoc bool) []string {
+	lines := summaryLines(doc)
+
+	var tagLines []string
+	for _, p := range tags.Params {
+		desc := doc.Params[p.Name]
+		if tsdoc {
+			tagLines = append(tagLines, strings.TrimSuffix("@param "+p.Name+" - "+desc, " - "))
+			continue
+		}
+		name := p.Name
+		if p.Optional {
+			name = "[" + name + "]"
+		}
+		line := "@param "
+		if p.Type != "" {
+			line += "{" + p.Type + "} "
+		}
+		tagLines = append(tagLines, strings.TrimSuffix(line+name+" - "+desc, " - "))
+	}
+	if tags.Returns {
+		line := "@returns"
+		if tags.ReturnType != "" && !tsdoc {
+			line += " {" + tags.ReturnType + "}"
+		}
+		tagLines = append(tagLines, strings.TrimSpace(line+" "+doc.Returns))
+	}
+	for _, exc := range tags.Throws {
+		ref := "{" + exc + "}"
+		if tsdoc {
+			ref = "{@link " + exc + "}"
+		}
+		tagLines = append(tagLines, strings.TrimSpace("@throws "+ref+" "+doc.Raises[exc]))
+	}
+	lines = section(lines, tagLines...)
+
+	for _, example := range doc.Examples {
+		block := []string{"@example"}
+		if tsdoc {
+			block = append(block, "```ts")
+		}
+		block = append(block, strings.Split(example, "\n")...)
+		if tsdoc {
+			block = append(block, "```")
+		}
+		lines = section(lines, block...)
+	}
+	return lines
+}
+
+// goDoc lays out a Go doc comment: prose, with examples as indented code
+// blocks.
+func goDoc(doc StructuredDoc) []string {
+	lines := summaryLines(doc)
+	if examples := strings.Join(doc.Examples, "\n"); examples != "" {
+		lines = section(lines, "For example:", "")
+		lines = append(lines, indent("\t", examples)...)
+	}
+	return lines
+}
@@ -0,0 +1,115 @@
+package llm
+
+import "testing"
+
+func TestFormatStructured(t *testing.T) {
+	content := `{"summary": "Adds two numbers.", "params": {"a": "First.", "b": "Second."}, "returns": "The sum.", "raises": {"ValueError": "If a is negative."}, "examples": ["add(1, 2)"]}`
+	tags := DocTags{
+		Params:     []DocParam{{Name: "a", Type: "int"}, {Name: "b", Type: "int", Optional: true}},
+		Returns:    true,
+		ReturnType: "int",
+		Throws:     []string{"ValueError"},
+	}
+
+	tests := []struct {
+		language, format string
+		want             string
+	}{
+		{"python", "google", `"""Adds two numbers.
+
+Args:
+    a (int): First.
+    b (int, optional): Second.
+
+Returns:
+    int: The sum.
+
+Raises:
+    ValueError: If a is negative.
+
+Examples:
+    add(1, 2)
+"""`},
+		{"python", "numpy", `"""Adds two numbers.
+
+Parameters
+----------
+a : int
+    First.
+b : int, optional
+    Second.
+
+Returns
+-------
+int
+    The sum.
+
+Raises
+------
+ValueError
+    If a is negative.
+
+Examples
+--------
+add(1, 2)
+"""`},
+		{"python", "sphinx", `"""Adds two numbers.
+
+:param a: First.
+:type a: int
+:param b: Second.
+:type b: int
+:returns: The sum.
+:rtype: int
+:raises ValueError: If a is negative.
+
+Example::
+
+    add(1, 2)
+"""`},
+		{"javascript", "jsdoc", `/**
+ * Adds two numbers.
+ *
+ * @param {int} a - First.
+ * @param {int} [b] - Second.
+ * @returns {int} The sum.
+ * @throws {ValueError} If a is negative.
+ *
+ * @example
+ * add(1, 2)
+ */`},
+		{"typescript", "tsdoc", "/**\n * Adds two numbers.\n *\n * @param a - First.\n * @param b - Second.\n * @returns The sum.\n * @throws {@link ValueError} If a is negative.\n *\n * @example\n * ```ts\n * add(1, 2)\n * ```\n */"},
+		{"go", "godoc", "// Adds two numbers.\n//\n// For example:\n//\n//\tadd(1, 2)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := FormatStructured(content, tt.language, tt.format, tags); got != tt.want {
+				t.Errorf("FormatStructured() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatStructuredSingleLine(t *testing.T) {
+	got := FormatStructured(`{"summary": "Does nothing."}`, "python", "google", DocTags{})
+	if want := `"""Does nothing."""`; got != want {
+		t.Errorf("FormatStructured() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateDocFormats(t *testing.T) {
+	tests := []struct {
+		formats map[string]string
+		ok      bool
+	}{
+		{map[string]string{"python": "numpy", "typescript": "tsdoc"}, true},
+		{map[string]string{"python": "rest"}, true},
+		{map[string]string{"python": "jsdoc"}, false},
+		{map[string]string{"cobol": "google"}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateDocFormats(tt.formats); (err == nil) != tt.ok {
+			t.Errorf("ValidateDocFormats(%v) = %v, want ok %v", tt.formats, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GoDocName makes a formatted Go comment start with name, as Go doc comments
+// should: "// Returns the sum." becomes "// Sum returns the sum."
+func GoDocName(comment, name string) string {
+	for _, prefix := range []string{"// ", "/* "} {
+		if rest, ok := strings.CutPrefix(comment, prefix); ok {
+			return prefix + startWithName(rest, name)
+		}
+	}
+	return comment
+}
+
+// startWithName rewrites the opening of text to name. name may be several
+// words, as in "Package foo".
+func startWithName(text, name string) string {
+	if name == "" || text == "" {
+		return text
+	}
+	if strings.HasPrefix(text, name+" ") || strings.HasPrefix(text, name+"\n") || text == name {
+		return text
+	}
+	if lead, _, ok := strings.Cut(name, " "); ok {
+		// "Package bar does" where "Package foo" was asked for.
+		n := len(strings.Fields(name))
+		if parts := strings.SplitN(text, " ", n+1); len(parts) == n+1 && strings.EqualFold(parts[0], lead) {
+			return name + " " + parts[n]
+		}
+	}
+
+	words := strings.SplitN(text, " ", 3)
+	first := strings.Trim(words[0], "`*'\".,:")
+	rest := strings.Join(words[1:], " ")
+	next := ""
+	if len(words) > 1 {
+		next = strings.Trim(words[1], "`*'\".,:")
+	}
+
+	switch {
+	case strings.EqualFold(first, name):
+		// Quoted or in the wrong case.
+		return name + " " + rest
+	case strings.HasSuffix(first, "."+name):
+		// Qualified with the receiver, as in (*Client).Do.
+		return name + " " + rest
+	case (strings.EqualFold(first, "a") || strings.EqualFold(first, "an") || strings.EqualFold(first, "the")) && next == name:
+		// "A Client is ..." is also conventional.
+		return text
+	case strings.EqualFold(first, "this") && len(words) > 2:
+		switch strings.ToLower(next) {
+		case "function", "func", "method", "type", "struct", "interface", "package", "command":
+			return name + " " + words[2]
+		}
+	}
+
+	if plainWord(words[0]) {
+		words[0] = strings.ToLower(words[0][:1]) + words[0][1:]
+	}
+	return name + " " + strings.Join(words, " ")
+}
+
+// plainWord reports whether word is an ordinary capitalised word, rather
+// than an acronym or identifier whose case matters.
+func plainWord(word string) bool {
+	for i, r := range word {
+		if !unicode.IsLetter(r) || (i > 0 && unicode.IsUpper(r)) {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// PackageTarget is a Go package to write a package comment for.
+type PackageTarget struct {
+	// Name is the package name, or the command name for package main.
+	Name    string
+	Command bool
+	Files   []string
+	// Declarations lists the first line of each exported declaration.
+	Declarations string
+}
+
+// PackageDocPrefix is how the package comment for target must begin. A
+// command's begins with its name capitalised, as in "Gofmt formats Go
+// programs".
+func PackageDocPrefix(target PackageTarget) string {
+	if target.Command {
+		r, size := utf8.DecodeRuneInString(target.Name)
+		return string(unicode.ToUpper(r)) + target.Name[size:]
+	}
+	return "Package " + target.Name
+}
+
+func BuildPackagePrompt(target PackageTarget) []Message {
+	systemPrompt := `You are a code documentation expert. Write package comments for Go packages.
+Rules:
+- Be brief but informative
+- Say what the package is for, not how it is implemented
+- Return ONLY the comment text, no code
+- Do not include comment delimiters (like // or /* */)
+- Maximum 2-4 sentences`
+
+	userPrompt := fmt.Sprintf(`Files: %s
+
+Exported declarations:
+%s
+
+Write the package comment. It must start with %q.`,
+		strings.Join(target.Files, ", "),
+		target.Declarations,
+		PackageDocPrefix(target),
+	)
+	if target.Command {
+		userPrompt += fmt.Sprintf("\nThis is the %s command: say what running it does.", target.Name)
+	}
+
+	return []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+}
+
+// FormatPackageDoc formats a package comment, making sure it starts the way
+// go doc expects.
+func FormatPackageDoc(comment string, target PackageTarget) string {
+	return formatLineComment(startWithName(strings.TrimSpace(comment), PackageDocPrefix(target)), "go")
+}
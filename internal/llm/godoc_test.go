@@ -0,0 +1,46 @@
+package llm
+
+import "testing"
+
+func TestGoDocName(t *testing.T) {
+	tests := []struct {
+		comment, name, want string
+	}{
+		{"// Returns the sum.", "Sum", "// Sum returns the sum."},
+		{"// Sum returns the sum.", "Sum", "// Sum returns the sum."},
+		{"// `sum` returns the sum.", "Sum", "// Sum returns the sum."},
+		{"// (*Client).Do sends a request.", "Do", "// Do sends a request."},
+		{"// A Client talks to the API.", "Client", "// A Client talks to the API."},
+		{"// This function parses input.", "Parse", "// Parse parses input."},
+		{"// HTTP handler for uploads.", "Upload", "// Upload HTTP handler for uploads."},
+		{"// Package bar does things.", "Package foo", "// Package foo does things."},
+		{"/* Returns the sum. */", "Sum", "/* Sum returns the sum. */"},
+		{"# not a Go comment", "Sum", "# not a Go comment"},
+	}
+	for _, tt := range tests {
+		if got := GoDocName(tt.comment, tt.name); got != tt.want {
+			t.Errorf("GoDocName(%q, %q) = %q, want %q", tt.comment, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPackageDocPrefix(t *testing.T) {
+	tests := []struct {
+		target PackageTarget
+		want   string
+	}{
+		{PackageTarget{Name: "parser"}, "Package parser"},
+		{PackageTarget{Name: "gofmt", Command: true}, "Gofmt"},
+		{PackageTarget{Name: "annotr", Command: true}, "Annotr"},
+	}
+	for _, tt := range tests {
+		if got := PackageDocPrefix(tt.target); got != tt.want {
+			t.Errorf("PackageDocPrefix(%+v) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+
+	command := PackageTarget{Name: "gofmt", Command: true}
+	if got := FormatPackageDoc("gofmt formats Go programs.", command); got != "// Gofmt formats Go programs." {
+		t.Errorf("FormatPackageDoc() = %q, want it to start with Gofmt", got)
+	}
+}
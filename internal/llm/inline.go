@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inlineKinds describes each kind of inline statement to the model.
+var inlineKinds = map[string]string{
+	"conditional": "a complex conditional",
+	"loop":        "a long or nested loop",
+	"error":       "non-trivial error handling",
+	"regex":       "a regular expression",
+	"bitwise":     "bit manipulation",
+}
+
+func buildInlinePrompt(target CommentTarget) []Message {
+	systemPrompt := `You are a code documentation expert. Write short inline comments that explain tricky statements inside functions.
+Rules:
+- One short line, at most 100 characters
+- Explain the intent or the non-obvious detail, not the syntax
+- For a regular expression, say what it matches
+- Return ONLY the comment text, no code
+- Do not include comment delimiters (like // or #)`
+
+	userPrompt := fmt.Sprintf(`Language: %s
+File: %s
+
+Context:
+%s
+
+Statement (%s, in %s):
+%s
+
+Write an inline comment for the statement:`,
+		target.Language,
+		target.Filename,
+		target.Context,
+		inlineKinds[target.Inline],
+		target.Name,
+		target.Code,
+	)
+
+	return []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+}
+
+// FormatInlineComment formats an inline comment as line comments, keeping
+// only the first line of text the model wrote and any provenance marker.
+func FormatInlineComment(comment, language string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(comment), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(lines) == 0 || markerPattern.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	prefix := getLineCommentPrefix(language)
+	if language == "rust" {
+		// /// would make it a doc comment.
+		prefix = "//"
+	}
+	for i, line := range lines {
+		lines[i] = prefix + " " + line
+	}
+	return strings.Join(lines, "\n")
+}
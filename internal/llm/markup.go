@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"strings"
+)
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// FormatXMLDoc formats comment as a C# /// XML doc comment, with a <param>
+// for each parameter, <returns> and an <exception> for each exception
+// thrown.
+func FormatXMLDoc(comment string, tags DocTags) string {
+	doc := parseTagged(comment)
+	var lines []string
+	if len(doc.summary) > 0 {
+		lines = append(lines, "<summary>")
+		for _, line := range doc.summary {
+			lines = append(lines, xmlEscaper.Replace(line))
+		}
+		lines = append(lines, "</summary>")
+	}
+	for _, param := range tags.Params {
+		lines = append(lines, `<param name="`+param.Name+`">`+xmlEscaper.Replace(doc.described["@param "+param.Name])+"</param>")
+	}
+	if tags.Returns {
+		lines = append(lines, "<returns>"+xmlEscaper.Replace(doc.described["@return"])+"</returns>")
+	}
+	for _, throws := range tags.Throws {
+		lines = append(lines, `<exception cref="`+throws+`">`+xmlEscaper.Replace(doc.described["@throws "+throws])+"</exception>")
+	}
+	if doc.marker != "" {
+		lines = append(lines, doc.marker)
+	}
+	return tripleSlash(lines)
+}
+
+// FormatSwiftMarkup formats comment as Swift /// markup with - Parameter,
+// - Returns: and - Throws: callouts, as Xcode's Quick Help shows them.
+func FormatSwiftMarkup(comment string, tags DocTags) string {
+	doc := parseTagged(comment)
+	var callouts []string
+	switch len(tags.Params) {
+	case 0:
+	case 1:
+		param := tags.Params[0]
+		callouts = append(callouts, joinTag("- Parameter "+param.Name+":", doc.described["@param "+param.Name]))
+	default:
+		callouts = append(callouts, "- Parameters:")
+		for _, param := range tags.Params {
+			callouts = append(callouts, joinTag("  - "+param.Name+":", doc.described["@param "+param.Name]))
+		}
+	}
+	if tags.Returns {
+		callouts = append(callouts, joinTag("- Returns:", doc.described["@return"]))
+	}
+	// Swift functions declare that they throw, not what; describe it once.
+	var throws []string
+	for _, t := range tags.Throws {
+		if text := doc.described["@throws "+t]; text != "" {
+			throws = append(throws, text)
+		}
+	}
+	if len(tags.Throws) > 0 {
+		callouts = append(callouts, joinTag("- Throws:", strings.Join(throws, " ")))
+	}
+	return tripleSlash(doc.lines(doc.summary, callouts))
+}
+
+func tripleSlash(lines []string) string {
+	var result []string
+	for _, line := range lines {
+		result = append(result, strings.TrimRight("/// "+line, " "))
+	}
+	return strings.Join(result, "\n")
+}
@@ -11,9 +11,69 @@ type CommentTarget struct {
 	Code         string
 	Context      string
 	CommentStyle string
+	// Name is the identifier the code declares.
+	Name string
+	// Tags, when set, asks for a description of each Javadoc-style tag.
+	Tags *DocTags
+	// Format, when set, asks for the parts of a structured docstring in
+	// that format as JSON instead of comment text.
+	Format string
+	// Inline, when set, is the kind of statement inside a function body
+	// to write a short inline comment for, such as "loop" or "regex".
+	Inline string
+	// Script, when set, asks for the header comment of a whole shell
+	// script, given the inputs found in it.
+	Script *ScriptInputs
+	// Dialect is the SQL dialect of the code, such as postgres, if known.
+	Dialect string
+	// Columns, when set, asks for a description of each column of a SQL
+	// table.
+	Columns []string
+	// Returns, when set, is the type a SQL function returns, for the
+	// description to cover.
+	Returns string
+}
+
+// DocTags lists the tags of a Javadoc or KDoc comment. They come from the
+// parsed signature, never from the model, so they always match the code.
+type DocTags struct {
+	Params     []DocParam
+	Returns    bool
+	ReturnType string
+	Throws     []string
+}
+
+type DocParam struct {
+	Name     string
+	Type     string
+	Optional bool
+}
+
+func (t DocTags) lines() []string {
+	var lines []string
+	for _, param := range t.Params {
+		lines = append(lines, "@param "+param.Name)
+	}
+	if t.Returns {
+		lines = append(lines, "@return")
+	}
+	for _, throws := range t.Throws {
+		lines = append(lines, "@throws "+throws)
+	}
+	return lines
 }
 
 func BuildCommentPrompt(target CommentTarget) []Message {
+	if target.Inline != "" {
+		return buildInlinePrompt(target)
+	}
+	if target.Script != nil {
+		return buildScriptPrompt(target)
+	}
+	if target.Format != "" {
+		return buildStructuredPrompt(target)
+	}
+
 	systemPrompt := `You are a code documentation expert. Generate concise, accurate comments for code blocks.
 Rules:
 - Be brief but informative
@@ -42,6 +102,32 @@ Generate a comment for the target code:`,
 		target.Code,
 	)
 
+	if target.Language == "go" && target.Name != "" {
+		userPrompt += fmt.Sprintf("\n\nStart the comment with the name %q followed by a verb, as Go doc comments do. For a method, use the method name alone, without the receiver.", target.Name)
+	}
+
+	if dialect := sqlDialects[target.Dialect]; dialect != "" {
+		userPrompt += fmt.Sprintf("\n\nThe SQL is written for %s.", dialect)
+	}
+
+	if target.Returns != "" {
+		userPrompt += fmt.Sprintf("\n\nThe function returns %s; end the description by saying what the returned value holds.", target.Returns)
+	}
+
+	if len(target.Columns) > 0 {
+		var columns []string
+		for _, column := range target.Columns {
+			columns = append(columns, unquoteColumn.Replace(column)+":")
+		}
+		userPrompt += "\n\nAfter the description, add one line per column saying what it holds, starting exactly with:\n" + strings.Join(columns, "\n")
+	}
+
+	if target.Tags != nil {
+		if tags := target.Tags.lines(); len(tags) > 0 {
+			userPrompt += "\n\nAfter the description, add one line per tag with a short description, starting exactly with:\n" + strings.Join(tags, "\n")
+		}
+	}
+
 	return []Message{
 		{Role: "system", Content: systemPrompt},
 		{Role: "user", Content: userPrompt},
@@ -63,17 +149,191 @@ func FormatComment(comment, language, style string) string {
 	}
 }
 
+// FormatJavadoc formats comment as a /** */ Javadoc or KDoc comment with one
+// tag per entry in tags. Descriptions are taken from "@tag name text" lines
+// the model wrote; tags it invented are dropped.
+func FormatJavadoc(comment string, tags DocTags) string {
+	return formatTagged(comment, tags, false)
+}
+
+// FormatDoxygen is FormatJavadoc for C and C++, opening the summary with
+// @brief.
+func FormatDoxygen(comment string, tags DocTags) string {
+	return formatTagged(comment, tags, true)
+}
+
+func formatTagged(comment string, tags DocTags, brief bool) string {
+	doc := parseTagged(comment)
+	summary := doc.summary
+	if brief && len(summary) > 0 && summary[0] != "" {
+		summary[0] = "@brief " + summary[0]
+	}
+
+	var tagLines []string
+	for _, tag := range tags.lines() {
+		tagLines = append(tagLines, strings.TrimSpace(tag+" "+doc.described[tag]))
+	}
+	return docBlock(doc.lines(summary, tagLines))
+}
+
+// FormatYARD formats comment as # comments with YARD tags for Ruby. Ruby
+// has no declared types, so only exceptions and predicates get one.
+func FormatYARD(comment string, tags DocTags) string {
+	doc := parseTagged(comment)
+	var tagLines []string
+	for _, param := range tags.Params {
+		tagLines = append(tagLines, joinTag("@param", param.Name, doc.described["@param "+param.Name]))
+	}
+	if tags.Returns {
+		returnType := ""
+		if tags.ReturnType != "" {
+			returnType = "[" + tags.ReturnType + "]"
+		}
+		tagLines = append(tagLines, joinTag("@return", returnType, doc.described["@return"]))
+	}
+	for _, throws := range tags.Throws {
+		tagLines = append(tagLines, joinTag("@raise", "["+throws+"]", doc.described["@throws "+throws]))
+	}
+
+	var result []string
+	for _, line := range doc.lines(doc.summary, tagLines) {
+		result = append(result, strings.TrimRight("# "+line, " "))
+	}
+	return strings.Join(result, "\n")
+}
+
+// FormatPHPDoc formats comment as a /** */ PHPDoc comment, with the
+// declared type before each parameter name.
+func FormatPHPDoc(comment string, tags DocTags) string {
+	doc := parseTagged(comment)
+	var tagLines []string
+	for _, param := range tags.Params {
+		tagLines = append(tagLines, joinTag("@param", param.Type, param.Name, doc.described["@param "+param.Name]))
+	}
+	if tags.Returns {
+		tagLines = append(tagLines, joinTag("@return", tags.ReturnType, doc.described["@return"]))
+	}
+	for _, throws := range tags.Throws {
+		tagLines = append(tagLines, joinTag("@throws", throws, doc.described["@throws "+throws]))
+	}
+	return docBlock(doc.lines(doc.summary, tagLines))
+}
+
+// taggedDoc is a tagged doc comment as the model wrote it.
+type taggedDoc struct {
+	summary []string
+	// described maps "@param name", "@return" and "@throws name" to the
+	// text after them.
+	described map[string]string
+	marker    string
+}
+
+func parseTagged(comment string) taggedDoc {
+	doc := taggedDoc{described: map[string]string{}}
+	for _, line := range strings.Split(strings.TrimSpace(comment), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case markerPattern.MatchString(line):
+			doc.marker = line
+		case strings.HasPrefix(line, "@"):
+			fields := strings.Fields(line)
+			key, rest := fields[0], fields[1:]
+			// Drop a type the model added anyway: YARD's [String] or
+			// PHPDoc's type before the $name.
+			if len(rest) > 0 && strings.HasPrefix(rest[0], "[") && strings.HasSuffix(rest[0], "]") {
+				rest = rest[1:]
+			}
+			if len(rest) > 1 && strings.HasPrefix(rest[1], "$") && !strings.HasPrefix(rest[0], "$") {
+				rest = rest[1:]
+			}
+			switch key {
+			case "@returns":
+				key = "@return"
+			case "@raise", "@raises", "@exception":
+				key = "@throws"
+			}
+			if key != "@return" && len(rest) > 0 {
+				key += " " + rest[0]
+				rest = rest[1:]
+			}
+			doc.described[key] = strings.Join(rest, " ")
+		default:
+			doc.summary = append(doc.summary, line)
+		}
+	}
+	return doc
+}
+
+// lines lays out summary, then the tags after a blank line, then the
+// marker.
+func (d taggedDoc) lines(summary, tagLines []string) []string {
+	lines := summary
+	if len(tagLines) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, tagLines...)
+	}
+	if d.marker != "" {
+		lines = append(lines, d.marker)
+	}
+	return lines
+}
+
+// joinTag joins the non-empty parts of a tag line.
+func joinTag(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// docBlock wraps lines in a /** */ comment.
+func docBlock(lines []string) string {
+	result := []string{"/**"}
+	for _, line := range lines {
+		result = append(result, strings.TrimRight(" * "+line, " "))
+	}
+	result = append(result, " */")
+	return strings.Join(result, "\n")
+}
+
+// FormatInnerDoc formats a doc comment that is written inside the item it
+// documents, such as Rust's //! module docs.
+func FormatInnerDoc(comment, language string) string {
+	comment = strings.TrimSpace(comment)
+	if language != "rust" {
+		return formatLineComment(comment, language)
+	}
+	lines := strings.Split(comment, "\n")
+	var result []string
+	for _, line := range lines {
+		result = append(result, "//! "+strings.TrimSpace(line))
+	}
+	return strings.Join(result, "\n")
+}
+
 func formatLineComment(comment, language string) string {
 	prefix := getLineCommentPrefix(language)
 	lines := strings.Split(comment, "\n")
 	var result []string
 	for _, line := range lines {
-		result = append(result, prefix+" "+strings.TrimSpace(line))
+		result = append(result, strings.TrimRight(prefix+" "+strings.TrimSpace(line), " "))
 	}
 	return strings.Join(result, "\n")
 }
 
 func formatBlockComment(comment, language string) string {
+	switch language {
+	case "ruby", "bash":
+		// Shell has no block comments, and Ruby's =begin and =end only
+		// work at the start of a line, which an indented method cannot
+		// give them.
+		return formatLineComment(comment, language)
+	}
 	start, end := getBlockCommentDelimiters(language)
 	return fmt.Sprintf("%s %s %s", start, comment, end)
 }
@@ -81,14 +341,13 @@ func formatBlockComment(comment, language string) string {
 func formatDocstring(comment, language string) string {
 	switch language {
 	case "python":
-		return fmt.Sprintf(`"""%s"""`, comment)
-	case "go":
-		lines := strings.Split(comment, "\n")
-		var result []string
-		for _, line := range lines {
-			result = append(result, "// "+strings.TrimSpace(line))
+		// PEP 257: a multi-line docstring closes on a line of its own.
+		if strings.Contains(comment, "\n") {
+			return fmt.Sprintf("\"\"\"%s\n\"\"\"", comment)
 		}
-		return strings.Join(result, "\n")
+		return fmt.Sprintf(`"""%s"""`, comment)
+	case "go", "rust", "ruby", "bash":
+		return formatLineComment(comment, language)
 	default:
 		return formatBlockComment(comment, language)
 	}
@@ -98,7 +357,12 @@ func getLineCommentPrefix(language string) string {
 	switch language {
 	case "python", "ruby", "shell", "bash", "yaml":
 		return "#"
+	case "sql":
+		return "--"
+	case "rust":
+		return "///"
 	default:
+		// C-family languages, PHP included.
 		return "//"
 	}
 }
@@ -109,6 +373,8 @@ func getBlockCommentDelimiters(language string) (string, string) {
 		return `"""`, `"""`
 	case "html", "xml":
 		return "<!--", "-->"
+	case "rust":
+		return "/**", "*/"
 	default:
 		return "/*", "*/"
 	}
@@ -0,0 +1,22 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCommentPromptSQL(t *testing.T) {
+	messages := BuildCommentPrompt(CommentTarget{
+		Language: "sql",
+		Filename: "schema.sql",
+		Code:     "CREATE FUNCTION add_one(x integer) RETURNS integer AS $$ SELECT x + 1 $$ LANGUAGE sql;",
+		Dialect:  "postgres",
+		Returns:  "integer",
+	})
+	user := messages[len(messages)-1].Content
+	for _, want := range []string{"PostgreSQL", "returns integer"} {
+		if !strings.Contains(user, want) {
+			t.Errorf("prompt does not mention %q:\n%s", want, user)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Provenance identifies a comment annotr generated: the model that wrote it
+// and a hash of the code it describes.
+type Provenance struct {
+	Model string
+	Hash  string
+}
+
+var markerPattern = regexp.MustCompile(`annotr:gen model=(\S+) hash=([0-9a-f]+)`)
+
+// Marker is the line appended to a generated comment's text before it is
+// formatted, so it ends up inside the comment in any style.
+func (p Provenance) Marker() string {
+	return fmt.Sprintf("annotr:gen model=%s hash=%s", p.Model, p.Hash)
+}
+
+// WithMarker appends the provenance marker to comment text.
+func WithMarker(comment string, p Provenance) string {
+	return strings.TrimSpace(comment) + "\n" + p.Marker()
+}
+
+// ParseProvenance extracts the marker from an existing comment, if it has one.
+func ParseProvenance(comment string) (Provenance, bool) {
+	m := markerPattern.FindStringSubmatch(comment)
+	if m == nil {
+		return Provenance{}, false
+	}
+	return Provenance{Model: m[1], Hash: m[2]}, true
+}
+
+// CodeHash fingerprints code for provenance markers. Whitespace is ignored so
+// reindenting or reflowing a block does not make its comment stale.
+func CodeHash(code string) string {
+	stripped := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, code)
+	sum := sha256.Sum256([]byte(stripped))
+	return hex.EncodeToString(sum[:])[:12]
+}
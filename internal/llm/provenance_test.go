@@ -0,0 +1,25 @@
+package llm
+
+import "testing"
+
+func TestProvenanceRoundTrip(t *testing.T) {
+	p := Provenance{Model: "llama3.2:3b", Hash: CodeHash("func A() {}")}
+	comment := FormatComment(WithMarker("A does nothing.", p), "go", "line")
+
+	got, ok := ParseProvenance(comment)
+	if !ok || got != p {
+		t.Errorf("ParseProvenance(%q) = %+v, %v, want %+v", comment, got, ok, p)
+	}
+	if text := StripDelimiters(comment); text != "A does nothing." {
+		t.Errorf("StripDelimiters(%q) = %q", comment, text)
+	}
+}
+
+func TestCodeHashIgnoresWhitespace(t *testing.T) {
+	if CodeHash("func A() {\n\treturn\n}") != CodeHash("func A() { return }") {
+		t.Error("reindenting code changed its hash")
+	}
+	if CodeHash("func A() {}") == CodeHash("func B() {}") {
+		t.Error("different code has the same hash")
+	}
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type RefreshTarget struct {
+	Language string
+	Filename string
+	Code     string
+	Comment  string
+}
+
+// RefreshVerdict is the model's judgement of an existing comment.
+type RefreshVerdict struct {
+	Accurate bool   `json:"accurate"`
+	Reason   string `json:"reason"`
+	Comment  string `json:"comment"`
+}
+
+func BuildRefreshPrompt(target RefreshTarget) []Message {
+	systemPrompt := `You are a code documentation expert. You check whether existing comments still describe the code they are attached to.
+Rules:
+- A comment is outdated if it describes parameters, behaviour or return values the code no longer has
+- Minor wording issues are not a reason to mark a comment outdated
+- Reply with a single JSON object and nothing else:
+  {"accurate": true|false, "reason": "<one sentence>", "comment": "<replacement comment text>"}
+- Leave "comment" empty when the comment is accurate
+- The replacement must not include comment delimiters (like // or /* */)
+- Keep the replacement about as long as the original`
+
+	userPrompt := fmt.Sprintf(`Language: %s
+File: %s
+
+Existing comment:
+%s
+
+Code:
+%s
+
+Is the existing comment still accurate?`,
+		target.Language,
+		target.Filename,
+		target.Comment,
+		target.Code,
+	)
+
+	return []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+}
+
+// ParseRefreshResponse extracts the verdict from a model reply, tolerating
+// code fences or prose around the JSON object.
+func ParseRefreshResponse(content string) (RefreshVerdict, error) {
+	var verdict RefreshVerdict
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return verdict, fmt.Errorf("no JSON object in response: %q", content)
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &verdict); err != nil {
+		return verdict, fmt.Errorf("invalid JSON in response: %w", err)
+	}
+	if !verdict.Accurate && strings.TrimSpace(verdict.Comment) == "" {
+		return verdict, fmt.Errorf("response marks the comment outdated without a replacement")
+	}
+	return verdict, nil
+}
+
+var delimiterPattern = regexp.MustCompile(`^\s*(///?!?|#|--|/\*\*?|\*/|\*|"""|''')\s?`)
+
+// StripDelimiters turns a formatted comment back into plain text, dropping
+// comment markers and any provenance marker line.
+func StripDelimiters(comment string) string {
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = delimiterPattern.ReplaceAllString(line, "")
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "*/"))
+		line = strings.TrimSuffix(strings.TrimSuffix(line, `"""`), "'''")
+		if markerPattern.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
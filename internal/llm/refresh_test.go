@@ -0,0 +1,20 @@
+package llm
+
+import "testing"
+
+func TestParseRefreshResponse(t *testing.T) {
+	tests := []struct {
+		content string
+		ok      bool
+	}{
+		{"```json\n{\"accurate\": true}\n```", true},
+		{`{"accurate": false, "comment": "Now returns an error."}`, true},
+		{`{"accurate": false}`, false},
+		{"The comment is fine.", false},
+	}
+	for _, tt := range tests {
+		if _, err := ParseRefreshResponse(tt.content); (err == nil) != tt.ok {
+			t.Errorf("ParseRefreshResponse(%q) error = %v, want ok %v", tt.content, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScriptInputs are the inputs found in a shell script, for its header
+// comment.
+type ScriptInputs struct {
+	// Args are the positional arguments used, such as $1 or $@.
+	Args []string
+	// Env are the environment variables read but never set.
+	Env []string
+}
+
+func buildScriptPrompt(target CommentTarget) []Message {
+	systemPrompt := `You are a code documentation expert. Write header comments for shell scripts.
+Rules:
+- Start with one sentence saying what the script does
+- Then a "Usage:" line showing how to run it
+- Then describe the positional arguments and environment variables it reads, if any
+- Then list its side effects: files written or deleted, services, deployments, network calls
+- Return ONLY the comment text, no code
+- Do not include comment delimiters (like #)
+- Maximum 12 lines`
+
+	args := strings.Join(target.Script.Args, ", ")
+	if args == "" {
+		args = "none"
+	}
+	env := strings.Join(target.Script.Env, ", ")
+	if env == "" {
+		env = "none"
+	}
+
+	userPrompt := fmt.Sprintf(`Language: %s
+File: %s
+
+Positional arguments used: %s
+Environment variables read: %s
+
+Script:
+%s
+
+Write the header comment for the script:`,
+		target.Language,
+		target.Filename,
+		args,
+		env,
+		target.Code,
+	)
+
+	return []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+}
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sqlDialects names the SQL dialects for the model.
+var sqlDialects = map[string]string{
+	"postgres": "PostgreSQL",
+	"mysql":    "MySQL",
+}
+
+// columnLine matches a "column: description" line the model wrote for a
+// table, possibly bulleted or with the name quoted.
+var columnLine = regexp.MustCompile("^[-*•\\s]*[`\"\\[]?([\\w$]+)[`\"\\]]?\\s*[:–—-]\\s*(.*)$")
+
+var unquoteColumn = strings.NewReplacer("`", "", `"`, "", "[", "", "]", "")
+
+// parseColumns splits comment into the description and the text the model
+// wrote for each of columns, keyed by the column as given.
+func parseColumns(comment string, columns []string) taggedDoc {
+	doc := taggedDoc{described: map[string]string{}}
+	byName := map[string]string{}
+	for _, column := range columns {
+		byName[strings.ToLower(unquoteColumn.Replace(column))] = column
+	}
+	for _, line := range strings.Split(strings.TrimSpace(comment), "\n") {
+		line = strings.TrimSpace(line)
+		if markerPattern.MatchString(line) {
+			doc.marker = line
+			continue
+		}
+		if m := columnLine.FindStringSubmatch(line); m != nil {
+			if column, ok := byName[strings.ToLower(m[1])]; ok {
+				doc.described[column] = m[2]
+				continue
+			}
+		}
+		doc.summary = append(doc.summary, line)
+	}
+	return doc
+}
+
+// FormatSQLComment formats comment as -- comments. For a table, the
+// description is followed by a line for each of columns the model
+// described, in table order.
+func FormatSQLComment(comment string, columns []string) string {
+	doc := parseColumns(comment, columns)
+	var columnLines []string
+	for _, column := range columns {
+		if text := doc.described[column]; text != "" {
+			columnLines = append(columnLines, unquoteColumn.Replace(column)+": "+text)
+		}
+	}
+	return formatLineComment(strings.Join(doc.lines(doc.summary, columnLines), "\n"), "sql")
+}
+
+// SQLObject is what COMMENT ON statements document.
+type SQLObject struct {
+	// Kind is the kind of object COMMENT ON names, such as TABLE or
+	// MATERIALIZED VIEW.
+	Kind string
+	// Name is the object's name, followed by the argument types for a
+	// function or procedure, as in add_one(integer).
+	Name    string
+	Columns []string
+}
+
+// FormatCommentOn formats comment as COMMENT ON statements: one for object
+// and one for each of its columns the model described. A provenance marker
+// stays a -- comment, above them.
+func FormatCommentOn(comment string, object SQLObject) string {
+	doc := parseColumns(comment, object.Columns)
+	var lines []string
+	if doc.marker != "" {
+		lines = append(lines, "-- "+doc.marker)
+	}
+	var summary []string
+	for _, line := range doc.summary {
+		if line != "" {
+			summary = append(summary, line)
+		}
+	}
+	lines = append(lines, fmt.Sprintf("COMMENT ON %s %s IS %s;", object.Kind, object.Name, sqlString(strings.Join(summary, " "))))
+	for _, column := range object.Columns {
+		if text := doc.described[column]; text != "" {
+			lines = append(lines, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", object.Name, column, sqlString(text)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
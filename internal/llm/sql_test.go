@@ -0,0 +1,47 @@
+package llm
+
+import "testing"
+
+func TestFormatSQLComment(t *testing.T) {
+	comment := "Registered users.\n- id: Primary key.\n`email` - Login address.\nunknown: Ignored column."
+	got := FormatSQLComment(comment, []string{"id", `"email"`})
+	want := "-- Registered users.\n-- unknown: Ignored column.\n--\n-- id: Primary key.\n-- email: Login address."
+	if got != want {
+		t.Errorf("FormatSQLComment() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatCommentOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		object  SQLObject
+		want    string
+	}{
+		{
+			name:    "table",
+			comment: "The user's accounts.\nid: Key.",
+			object:  SQLObject{Kind: "TABLE", Name: "users", Columns: []string{"id", "email"}},
+			want:    "COMMENT ON TABLE users IS 'The user''s accounts.';\nCOMMENT ON COLUMN users.id IS 'Key.';",
+		},
+		{
+			name:    "function",
+			comment: "Counts users.\n\nReturns the total.",
+			object:  SQLObject{Kind: "FUNCTION", Name: "user_count()"},
+			want:    "COMMENT ON FUNCTION user_count() IS 'Counts users. Returns the total.';",
+		},
+		{
+			name:    "marker",
+			comment: WithMarker("Active users.", Provenance{Model: "m", Hash: "abc"}),
+			object:  SQLObject{Kind: "VIEW", Name: "active_users"},
+			want:    "-- " + Provenance{Model: "m", Hash: "abc"}.Marker() + "\nCOMMENT ON VIEW active_users IS 'Active users.';",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatCommentOn(tt.comment, tt.object); got != tt.want {
+				t.Errorf("FormatCommentOn() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
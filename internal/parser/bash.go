@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// shellShebang matches the first line of sh, bash and zsh scripts, which
+// are parsed with the bash grammar.
+var shellShebang = regexp.MustCompile(`^#!\s*\S*/(env\s+(-\S+\s+)*)?(ba|z)?sh\b`)
+
+// isShellScript reports whether the file at path starts with a shell
+// shebang, which is how scripts without an extension are recognised.
+func isShellScript(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 128)
+	n, _ := f.Read(buf)
+	line, _, _ := strings.Cut(string(buf[:n]), "\n")
+	return shellShebang.MatchString(line)
+}
+
+// envNames are the environment variables shell scripts read without them
+// being inputs worth documenting.
+var envNames = map[string]bool{
+	"HOME": true, "PATH": true, "PWD": true, "OLDPWD": true, "USER": true, "SHELL": true,
+	"IFS": true, "RANDOM": true, "LINENO": true, "SECONDS": true, "UID": true, "EUID": true,
+	"HOSTNAME": true, "BASH_SOURCE": true, "BASH_VERSION": true, "FUNCNAME": true,
+	"PIPESTATUS": true, "OPTARG": true, "OPTIND": true, "REPLY": true, "TMPDIR": true,
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// scriptBlock returns the block for the header comment of a shell script,
+// which describes the whole script. The comment goes after the shebang and
+// any directives such as # shellcheck, never above them. It returns false
+// for a script with no commands.
+func (p *Parser) scriptBlock(root *sitter.Node, source []byte) (CodeBlock, bool) {
+	hasCode := false
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		if !isCommentType(root.NamedChild(i).Type()) {
+			hasCode = true
+			break
+		}
+	}
+	if !hasCode {
+		return CodeBlock{}, false
+	}
+
+	// The header is the first run of comments that are not directives,
+	// possibly after blank lines.
+	var anchor uint32
+	var doc docSpan
+	var parts []string
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		n := root.NamedChild(i)
+		if !isCommentType(n.Type()) {
+			break
+		}
+		text := n.Content(source)
+		row := n.StartPoint().Row
+		if len(parts) > 0 && row != doc.endRow+1 {
+			break
+		}
+		if len(parts) == 0 {
+			if IsDirective(text, p.language) {
+				anchor = row + 1
+				continue
+			}
+			doc.startRow = row
+		}
+		parts = append(parts, text)
+		doc.endRow = row
+	}
+	doc.text = strings.Join(parts, "\n")
+
+	return CodeBlock{
+		Type:         "script",
+		Name:         filepath.Base(p.filename),
+		StartLine:    0,
+		EndLine:      root.EndPoint().Row,
+		StartByte:    0,
+		EndByte:      uint32(len(source)),
+		Code:         string(source),
+		AnchorLine:   anchor,
+		DocComment:   doc.text,
+		DocStartLine: doc.startRow,
+		DocEndLine:   doc.endRow,
+		Exported:     true,
+		Signature:    scriptInputs(root, source),
+	}, true
+}
+
+// scriptInputs finds what a shell script takes as input: the positional
+// arguments used outside functions, and the environment variables read
+// anywhere but never set by the script.
+func scriptInputs(root *sitter.Node, source []byte) Signature {
+	var sig Signature
+	args := map[string]bool{}
+	read := map[string]bool{}
+	set := map[string]bool{}
+
+	var walk func(n *sitter.Node, inFunction bool)
+	walk = func(n *sitter.Node, inFunction bool) {
+		switch n.Type() {
+		case "function_definition":
+			inFunction = true
+		case "simple_expansion", "expansion":
+			if n.NamedChildCount() > 0 {
+				name := n.NamedChild(0)
+				text := name.Content(source)
+				switch {
+				case name.Type() == "special_variable_name" && (text == "@" || text == "*"):
+					if !inFunction {
+						args["$@"] = true
+					}
+				case name.Type() == "variable_name" && text >= "1" && text <= "9" && len(text) == 1:
+					if !inFunction {
+						args["$"+text] = true
+					}
+				case name.Type() == "variable_name":
+					read[text] = true
+				}
+			}
+		case "variable_assignment":
+			set[fieldContent(n, "name", source)] = true
+		case "for_statement":
+			set[fieldContent(n, "variable", source)] = true
+		case "declaration_command", "unset_command":
+			for i := 0; i < int(n.NamedChildCount()); i++ {
+				if child := n.NamedChild(i); child.Type() == "variable_name" {
+					set[child.Content(source)] = true
+				}
+			}
+		case "command":
+			if fieldContent(n, "name", source) == "read" {
+				for i := 0; i < int(n.NamedChildCount()); i++ {
+					if arg := n.NamedChild(i); arg.Type() == "word" && !strings.HasPrefix(arg.Content(source), "-") {
+						set[arg.Content(source)] = true
+					}
+				}
+			}
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			walk(n.NamedChild(i), inFunction)
+		}
+	}
+	walk(root, false)
+
+	for arg := range args {
+		sig.Params = append(sig.Params, Param{Name: arg})
+	}
+	sort.Slice(sig.Params, func(i, j int) bool {
+		return sig.Params[i].Name < sig.Params[j].Name
+	})
+	for name := range read {
+		if !set[name] && !envNames[name] && envNamePattern.MatchString(name) {
+			sig.Env = append(sig.Env, name)
+		}
+	}
+	sort.Strings(sig.Env)
+	return sig
+}
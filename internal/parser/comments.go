@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Comment is a comment node, a Python docstring or a SQL COMMENT ON
+// statement, found in a source file.
+type Comment struct {
+	StartLine uint32
+	EndLine   uint32
+	StartByte uint32
+	EndByte   uint32
+	Text      string
+	// Docstring marks a Python string statement used as documentation.
+	// StartByte and EndByte then cover the whole expression statement.
+	Docstring bool
+	// OnlyStatement marks a docstring that is the sole statement of its
+	// body, so removing it would leave the body empty.
+	OnlyStatement bool
+}
+
+// Comments returns every comment in source, in order. SQL COMMENT ON
+// statements count as comments too. In Vue, Svelte and HTML files, only the
+// comments in scripts are returned.
+func (p *Parser) Comments(source []byte) ([]Comment, error) {
+	if p.host != "" {
+		return p.regionComments(source)
+	}
+	tree, err := p.parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+
+	var comments []Comment
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if isCommentType(n.Type()) {
+			endByte, endRow := commentEnd(n, source)
+			comments = append(comments, Comment{
+				StartLine: n.StartPoint().Row,
+				EndLine:   endRow,
+				StartByte: n.StartByte(),
+				EndByte:   endByte,
+				Text:      string(source[n.StartByte():endByte]),
+			})
+			return
+		}
+		if p.language == "python" && isDocstringStatement(n) {
+			comments = append(comments, Comment{
+				StartLine:     n.StartPoint().Row,
+				EndLine:       n.EndPoint().Row,
+				StartByte:     n.StartByte(),
+				EndByte:       n.EndByte(),
+				Text:          n.Content(source),
+				Docstring:     true,
+				OnlyStatement: n.Parent().Type() == "block" && countStatements(n.Parent()) == 1,
+			})
+			return
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			walk(n.Child(i))
+		}
+	}
+	walk(tree.RootNode())
+
+	if p.language == "sql" {
+		comments = append(comments, commentOnComments(source)...)
+		sort.SliceStable(comments, func(i, j int) bool {
+			return comments[i].StartByte < comments[j].StartByte
+		})
+	}
+	return comments, nil
+}
+
+// isDocstringStatement reports whether n is a lone string statement opening
+// a module, class or function body.
+func isDocstringStatement(n *sitter.Node) bool {
+	if n.Type() != "expression_statement" || n.NamedChildCount() != 1 || n.NamedChild(0).Type() != "string" {
+		return false
+	}
+
+	parent := n.Parent()
+	if parent == nil {
+		return false
+	}
+	switch parent.Type() {
+	case "module":
+	case "block":
+		owner := parent.Parent()
+		if owner == nil || (owner.Type() != "function_definition" && owner.Type() != "class_definition") {
+			return false
+		}
+	default:
+		return false
+	}
+
+	for i := 0; i < int(parent.NamedChildCount()); i++ {
+		stmt := parent.NamedChild(i)
+		if isCommentType(stmt.Type()) {
+			continue
+		}
+		return stmt.Equal(n)
+	}
+	return false
+}
+
+func countStatements(block *sitter.Node) int {
+	count := 0
+	for i := 0; i < int(block.NamedChildCount()); i++ {
+		if !isCommentType(block.NamedChild(i).Type()) {
+			count++
+		}
+	}
+	return count
+}
+
+var (
+	goGeneratedPattern      = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+	pythonEncodingPattern   = regexp.MustCompile(`^#.*?coding[:=][ \t]*[-_.a-zA-Z0-9]+`)
+	directivePrefixesByLang = map[string][]string{
+		"go": {
+			"//go:", "//line ", "//export ", "//extern ", "//nolint", "//lint:", "// +build",
+		},
+		"python": {
+			"#!", "# type:", "# noqa", "# pylint:", "# pragma:", "# fmt:", "# isort:",
+			"# mypy:", "# pyright:", "# -*-", "# vim:",
+		},
+		"javascript": {
+			"#!", "// @ts-", "/// <reference", "// eslint-", "/* eslint", "/*eslint", "// prettier-ignore",
+			"// @flow", "/* @flow", "/** @jsx", "/* istanbul ignore", "/* c8 ignore", "//# sourceMappingURL=",
+			"/*!", "/* webpack", "/* @vite-ignore",
+		},
+		"bash": {
+			"#!", "# shellcheck ", "# vim:", "# -*-",
+		},
+		"csharp": {
+			"// <auto-generated", "// ReSharper ", "// Resharper ", "// NOLINT", "// nullable",
+		},
+		"swift": {
+			"#!", "// swiftlint:", "// swift-format-ignore", "// MARK:", "// sourcery:",
+		},
+		"ruby": {
+			"#!", "# frozen_string_literal:", "# encoding:", "# coding:", "# -*-", "# rubocop:",
+			"# typed:", "# :nocov:", "# standard:", "# vim:",
+		},
+		"php": {
+			"#!", "// phpcs:", "# phpcs:", "// @phpstan-", "/** @phpstan-", "// @psalm-", "/** @psalm-",
+			"// @codingStandards", "/** @var ", "/* @var ", "// @codeCoverageIgnore",
+		},
+		"sql": {
+			"-- +goose", "-- +migrate", "-- migrate:", "-- name:", "--liquibase", "-- liquibase",
+			"--changeset", "-- changeset", "--rollback", "-- rollback", "-- noqa", "-- sqlfluff:",
+		},
+		"c": {
+			"// clang-format", "/* clang-format", "// NOLINT", "//NOLINT", "// IWYU pragma",
+			"// cppcheck-suppress",
+		},
+	}
+)
+
+// IsDirective reports whether a comment is an instruction to a tool rather
+// than documentation. annotr never removes or counts these as docs.
+func IsDirective(comment, language string) bool {
+	text := strings.TrimSpace(comment)
+	switch language {
+	case "go":
+		if goGeneratedPattern.MatchString(text) {
+			return true
+		}
+	case "python":
+		if pythonEncodingPattern.MatchString(text) {
+			return true
+		}
+	case "typescript":
+		language = "javascript"
+	case "cpp":
+		language = "c"
+	}
+
+	for _, prefix := range directivePrefixesByLang[language] {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package parser
+
+import "testing"
+
+func TestIsDirective(t *testing.T) {
+	tests := []struct {
+		comment, language string
+		want              bool
+	}{
+		{"//go:generate stringer -type=Kind", "go", true},
+		{"// Code generated by protoc. DO NOT EDIT.", "go", true},
+		{"// Kind is the kind of thing.", "go", false},
+		{"# -*- coding: utf-8 -*-", "python", true},
+		{"# noqa: E501", "python", true},
+		{"# Loads the config.", "python", false},
+		{"// @ts-expect-error", "typescript", true},
+		{"/* eslint-disable no-console */", "javascript", true},
+		{"// NOLINT(readability)", "cpp", true},
+		{"# shellcheck disable=SC2086", "bash", true},
+		{"-- +goose Up", "sql", true},
+		{"-- Orders placed today.", "sql", false},
+		{"# rubocop:disable Metrics", "ruby", true},
+	}
+	for _, tt := range tests {
+		if got := IsDirective(tt.comment, tt.language); got != tt.want {
+			t.Errorf("IsDirective(%q, %q) = %v, want %v", tt.comment, tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestCommentsPython(t *testing.T) {
+	source := `"""Module doc."""
+
+
+def f():
+    """Only statement."""
+
+
+def g():
+    """Docstring."""
+    # A comment.
+    x = "not a docstring"
+    return x
+`
+	p, err := NewParser("m.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments, err := p.Comments([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Comment{
+		{Text: `"""Module doc."""`, Docstring: true},
+		{Text: `"""Only statement."""`, Docstring: true, OnlyStatement: true},
+		{Text: `"""Docstring."""`, Docstring: true},
+		{Text: "# A comment."},
+	}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d: %+v", len(comments), len(want), comments)
+	}
+	for i, c := range comments {
+		if c.Text != want[i].Text || c.Docstring != want[i].Docstring || c.OnlyStatement != want[i].OnlyStatement {
+			t.Errorf("comment %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+}
@@ -20,7 +20,11 @@ func BuildContext(source []byte, block CodeBlock, contextLines int) string {
 	}
 
 	var contextParts []string
-	
+
+	if block.Parent != nil {
+		contextParts = append(contextParts, "// Inside:\n"+strings.Join(enclosingSignatures(block), "\n"))
+	}
+
 	if startLine < int(block.StartLine) {
 		beforeLines := lines[startLine:block.StartLine]
 		contextParts = append(contextParts, "// Before:\n"+strings.Join(beforeLines, "\n"))
@@ -75,3 +79,14 @@ func ExtractImports(source []byte, language string) string {
 
 	return strings.Join(imports, "\n")
 }
+
+// enclosingSignatures returns the first line of each block enclosing block,
+// outermost first, such as the class declaration around a method.
+func enclosingSignatures(block CodeBlock) []string {
+	var signatures []string
+	for parent := block.Parent; parent != nil; parent = parent.Parent {
+		line, _, _ := strings.Cut(parent.Code, "\n")
+		signatures = append([]string{strings.TrimSpace(line)}, signatures...)
+	}
+	return signatures
+}
@@ -0,0 +1,598 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// commentNode is a comment that sits on lines of its own, which is the only
+// kind that can document the declaration below it.
+type commentNode struct {
+	startRow  uint32
+	endRow    uint32
+	startByte uint32
+	endByte   uint32
+}
+
+// commentIndex maps the last row of each own-line comment to the comment.
+type commentIndex map[uint32]commentNode
+
+func indexComments(root *sitter.Node, source []byte) commentIndex {
+	index := commentIndex{}
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if isCommentType(n.Type()) {
+			endByte, endRow := commentEnd(n, source)
+			if onOwnLines(source, n.StartByte(), endByte) {
+				index[endRow] = commentNode{
+					startRow:  n.StartPoint().Row,
+					endRow:    endRow,
+					startByte: n.StartByte(),
+					endByte:   endByte,
+				}
+			}
+			return
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			walk(n.Child(i))
+		}
+	}
+	walk(root)
+	return index
+}
+
+func isCommentType(nodeType string) bool {
+	switch nodeType {
+	case "comment_statement", "keyword_comment":
+		// SQL's COMMENT ON, which is a statement.
+		return false
+	}
+	return strings.Contains(nodeType, "comment")
+}
+
+// commentEnd returns where comment node n ends, leaving out the trailing
+// newline that some grammars, like Rust's, include in line comments.
+func commentEnd(n *sitter.Node, source []byte) (uint32, uint32) {
+	end, row := n.EndByte(), n.EndPoint().Row
+	for end > n.StartByte() && (source[end-1] == '\n' || source[end-1] == '\r') {
+		if source[end-1] == '\n' {
+			row--
+		}
+		end--
+	}
+	return end, row
+}
+
+// onOwnLines reports whether only whitespace surrounds source[start:end] on
+// its first and last lines.
+func onOwnLines(source []byte, start, end uint32) bool {
+	for i := int(start) - 1; i >= 0 && source[i] != '\n'; i-- {
+		if source[i] != ' ' && source[i] != '\t' {
+			return false
+		}
+	}
+	for i := int(end); i < len(source) && source[i] != '\n'; i++ {
+		if source[i] != ' ' && source[i] != '\t' && source[i] != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// docSpan is an existing doc comment and the lines it occupies.
+type docSpan struct {
+	text     string
+	startRow uint32
+	endRow   uint32
+}
+
+// docAbove returns the run of comments directly above row, skipping tool
+// directives such as //go:generate that are not documentation.
+func (idx commentIndex) docAbove(row uint32, source []byte, language string) docSpan {
+	var doc docSpan
+	var parts []string
+	for row > 0 {
+		c, ok := idx[row-1]
+		if !ok {
+			break
+		}
+		text := string(source[c.startByte:c.endByte])
+		if language == "rust" && isInnerDoc(text) {
+			// Documents the enclosing module, not what follows.
+			break
+		}
+		if !IsDirective(text, language) {
+			parts = append([]string{text}, parts...)
+			if len(parts) == 1 {
+				doc.endRow = c.endRow
+			}
+			doc.startRow = c.startRow
+		}
+		row = c.startRow
+	}
+	doc.text = strings.Join(parts, "\n")
+	return doc
+}
+
+// rustDocAbove is docAbove for a Rust item, whose documentation is only its
+// /// and /** */ comments. Plain comments among them are passed over, as
+// rustdoc does.
+func (idx commentIndex) rustDocAbove(row uint32, source []byte) docSpan {
+	var doc docSpan
+	var parts []string
+	for row > 0 {
+		c, ok := idx[row-1]
+		if !ok {
+			break
+		}
+		text := string(source[c.startByte:c.endByte])
+		if isInnerDoc(text) {
+			break
+		}
+		if isOuterDoc(text) {
+			parts = append([]string{text}, parts...)
+			if len(parts) == 1 {
+				doc.endRow = c.endRow
+			}
+			doc.startRow = c.startRow
+		}
+		row = c.startRow
+	}
+	doc.text = strings.Join(parts, "\n")
+	return doc
+}
+
+// rustDocAttributes returns the #[doc = "..."] attributes on a Rust item,
+// the other way of writing its documentation.
+func rustDocAttributes(n *sitter.Node, source []byte) docSpan {
+	var doc docSpan
+	var parts []string
+	for prev := n.PrevSibling(); prev != nil && prev.Type() == "attribute_item"; prev = prev.PrevSibling() {
+		text := prev.Content(source)
+		if !rustDocAttribute.MatchString(text) {
+			continue
+		}
+		parts = append([]string{text}, parts...)
+		if len(parts) == 1 {
+			doc.endRow = prev.EndPoint().Row
+		}
+		doc.startRow = prev.StartPoint().Row
+	}
+	doc.text = strings.Join(parts, "\n")
+	return doc
+}
+
+var rustDocAttribute = regexp.MustCompile(`^#\[\s*doc\s*[=(]`)
+
+// anchorNode returns the node a doc comment for n is written above: the
+// outermost wrapper that only adds decorators, an export or a binding, or
+// the first of the attributes preceding it.
+func anchorNode(n *sitter.Node) *sitter.Node {
+	anchor := n
+	for parent := anchor.Parent(); parent != nil; parent = anchor.Parent() {
+		switch parent.Type() {
+		case "decorated_definition", "export_statement", "template_declaration", "type_definition":
+		case "variable_declarator", "lexical_declaration", "variable_declaration":
+			if parent.StartPoint().Row != anchor.StartPoint().Row {
+				return firstAttribute(anchor)
+			}
+		default:
+			return firstAttribute(anchor)
+		}
+		anchor = parent
+	}
+	return firstAttribute(anchor)
+}
+
+// firstAttribute walks back over the Rust attributes, such as #[derive],
+// that belong to n.
+func firstAttribute(n *sitter.Node) *sitter.Node {
+	for prev := n.PrevSibling(); prev != nil && prev.Type() == "attribute_item"; prev = prev.PrevSibling() {
+		n = prev
+	}
+	return n
+}
+
+// pythonDocstring returns the docstring of a Python function or class: a
+// string expression as the first statement of its body.
+func pythonDocstring(n *sitter.Node, source []byte) docSpan {
+	body := n.ChildByFieldName("body")
+	if body == nil {
+		return docSpan{}
+	}
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		stmt := body.NamedChild(i)
+		if isCommentType(stmt.Type()) {
+			continue
+		}
+		if stmt.Type() == "expression_statement" && stmt.NamedChildCount() == 1 && stmt.NamedChild(0).Type() == "string" {
+			return docSpan{
+				text:     stmt.NamedChild(0).Content(source),
+				startRow: stmt.StartPoint().Row,
+				endRow:   stmt.EndPoint().Row,
+			}
+		}
+		return docSpan{}
+	}
+	return docSpan{}
+}
+
+// pythonBodyStart returns where the docstring of a Python function or class
+// goes: the start of the first line after its signature, comments included,
+// or of the body itself when it shares the signature's line. It returns 0
+// if there is no body.
+func pythonBodyStart(n *sitter.Node) uint32 {
+	var colon *sitter.Node
+	for i := 0; i < int(n.ChildCount()); i++ {
+		child := n.Child(i)
+		switch {
+		case child.Type() == ":" && colon == nil:
+			colon = child
+		case colon == nil:
+		case child.StartPoint().Row > colon.StartPoint().Row || child.Type() == "block":
+			return child.StartByte()
+		}
+	}
+	return 0
+}
+
+// rustModuleBody returns the row of the opening brace of a Rust module whose
+// items start on a later line, so a //! doc comment fits right below it.
+func rustModuleBody(n *sitter.Node) (uint32, bool) {
+	body := n.ChildByFieldName("body")
+	if body == nil || body.NamedChildCount() == 0 {
+		return 0, false
+	}
+	if body.NamedChild(0).StartPoint().Row == body.StartPoint().Row {
+		return 0, false
+	}
+	return body.StartPoint().Row, true
+}
+
+// rustInnerDoc returns the //! or /*! comments opening a Rust module body.
+func rustInnerDoc(n *sitter.Node, source []byte) docSpan {
+	body := n.ChildByFieldName("body")
+	if body == nil {
+		return docSpan{}
+	}
+	var doc docSpan
+	var parts []string
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		c := body.NamedChild(i)
+		if !isCommentType(c.Type()) {
+			break
+		}
+		endByte, endRow := commentEnd(c, source)
+		text := string(source[c.StartByte():endByte])
+		if !isInnerDoc(text) {
+			break
+		}
+		if len(parts) == 0 {
+			doc.startRow = c.StartPoint().Row
+		}
+		doc.endRow = endRow
+		parts = append(parts, text)
+	}
+	doc.text = strings.Join(parts, "\n")
+	return doc
+}
+
+func isInnerDoc(comment string) bool {
+	return strings.HasPrefix(comment, "//!") || strings.HasPrefix(comment, "/*!")
+}
+
+// isOuterDoc reports whether a Rust comment documents the item below it:
+// /// or /** */, but not //// or /*** */, which rustdoc treats as plain.
+func isOuterDoc(comment string) bool {
+	switch {
+	case strings.HasPrefix(comment, "///"):
+		return !strings.HasPrefix(comment, "////")
+	case strings.HasPrefix(comment, "/**"):
+		return !strings.HasPrefix(comment, "/***") && comment != "/**/"
+	}
+	return false
+}
+
+func isExported(n *sitter.Node, name, language string, source []byte) bool {
+	switch language {
+	case "go":
+		for _, r := range name {
+			return unicode.IsUpper(r)
+		}
+		return false
+	case "python":
+		return !strings.HasPrefix(name, "_")
+	case "javascript", "typescript":
+		if strings.HasPrefix(name, "#") {
+			return false
+		}
+		return jsExported(n, name, source)
+	case "c", "cpp":
+		return cExported(n, source)
+	case "rust":
+		if hasVisibility(n) {
+			return true
+		}
+		// Trait items share the visibility of their trait.
+		if list := n.Parent(); list != nil && list.Type() == "declaration_list" {
+			if trait := list.Parent(); trait != nil && trait.Type() == "trait_item" {
+				return hasVisibility(trait)
+			}
+		}
+		return false
+	case "java":
+		if body := n.Parent(); body != nil && (body.Type() == "interface_body" || body.Type() == "annotation_type_body") {
+			return true
+		}
+		mods := modifierWords(n)
+		return mods["public"] || mods["protected"]
+	case "kotlin":
+		// Declarations are public unless marked otherwise.
+		mods := modifierWords(n)
+		return !mods["private"] && !mods["internal"]
+	case "csharp":
+		if body := n.Parent(); body != nil && body.Type() == "declaration_list" {
+			if owner := body.Parent(); owner != nil && owner.Type() == "interface_declaration" {
+				return true
+			}
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if mod := n.NamedChild(i); mod.Type() == "modifier" {
+				if word := mod.Content(source); word == "public" || word == "protected" {
+					return true
+				}
+			}
+		}
+		return false
+	case "swift":
+		// Declarations are internal to the module unless marked public or
+		// open; protocol requirements share the protocol's visibility.
+		if body := n.Parent(); body != nil && body.Type() == "protocol_body" {
+			if protocol := body.Parent(); protocol != nil {
+				return isExported(protocol, name, language, source)
+			}
+		}
+		if mods := findChild(n, "modifiers"); mods != nil {
+			if visibility := findChild(mods, "visibility_modifier"); visibility != nil {
+				word := visibility.Content(source)
+				return strings.HasPrefix(word, "public") || strings.HasPrefix(word, "open")
+			}
+		}
+		return false
+	case "ruby":
+		return !rubyPrivate(n, source)
+	case "php":
+		// Members without a visibility modifier are public.
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if mod := n.NamedChild(i); mod.Type() == "visibility_modifier" {
+				return mod.Content(source) == "public"
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// jsExported reports whether a JavaScript or TypeScript declaration is
+// exported, by its own export statement or by name elsewhere in the module.
+// Functions nested inside an exported one are not; members of an exported
+// class are, unless private.
+func jsExported(n *sitter.Node, name string, source []byte) bool {
+	parent := n.Parent()
+	if parent == nil {
+		return false
+	}
+	switch n.Type() {
+	case "arrow_function", "function_expression":
+		switch parent.Type() {
+		case "variable_declarator":
+			// const f = () => ... is exported with its declaration.
+			if decl := parent.Parent(); decl != nil {
+				return jsExported(decl, name, source)
+			}
+			return false
+		case "public_field_definition":
+			return jsExported(parent, name, source)
+		case "pair":
+			// module.exports = { f: () => ... }
+			if object := parent.Parent(); object != nil {
+				return isModuleExports(object.Parent(), source)
+			}
+			return false
+		}
+	case "method_definition", "public_field_definition":
+		if parent.Type() != "class_body" {
+			return false
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if mod := n.NamedChild(i); mod.Type() == "accessibility_modifier" && mod.Content(source) != "public" {
+				return false
+			}
+		}
+		if class := parent.Parent(); class != nil {
+			className := ""
+			if id := class.ChildByFieldName("name"); id != nil {
+				className = id.Content(source)
+			}
+			return jsExported(class, className, source)
+		}
+		return false
+	}
+	if parent.Type() == "export_statement" {
+		return true
+	}
+	return parent.Type() == "program" && name != "" && jsExportedNames(parent, source)[name]
+}
+
+// jsExportedNames returns the local names a module exports apart from their
+// declarations: in export { a, b as c } and export default a, and through
+// CommonJS module.exports = a, module.exports = { a, b: c } and
+// exports.b = a.
+func jsExportedNames(program *sitter.Node, source []byte) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < int(program.NamedChildCount()); i++ {
+		stmt := program.NamedChild(i)
+		switch stmt.Type() {
+		case "export_statement":
+			if stmt.ChildByFieldName("source") != nil {
+				// Re-exported from another module.
+				continue
+			}
+			if value := stmt.ChildByFieldName("value"); value != nil && value.Type() == "identifier" {
+				names[value.Content(source)] = true
+			}
+			clause := findChild(stmt, "export_clause")
+			if clause == nil {
+				continue
+			}
+			for j := 0; j < int(clause.NamedChildCount()); j++ {
+				if local := clause.NamedChild(j).ChildByFieldName("name"); local != nil {
+					names[local.Content(source)] = true
+				}
+			}
+		case "expression_statement":
+			assign := stmt.NamedChild(0)
+			if assign == nil || assign.Type() != "assignment_expression" {
+				continue
+			}
+			left, right := assign.ChildByFieldName("left"), assign.ChildByFieldName("right")
+			if left == nil || right == nil {
+				continue
+			}
+			switch {
+			case right.Type() == "identifier" && (isModuleExports(assign, source) || isExportsMember(left, source)):
+				names[right.Content(source)] = true
+			case right.Type() == "object" && isModuleExports(assign, source):
+				for j := 0; j < int(right.NamedChildCount()); j++ {
+					switch prop := right.NamedChild(j); prop.Type() {
+					case "shorthand_property_identifier":
+						names[prop.Content(source)] = true
+					case "pair":
+						if value := prop.ChildByFieldName("value"); value != nil && value.Type() == "identifier" {
+							names[value.Content(source)] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// isModuleExports reports whether n assigns to CommonJS module.exports.
+func isModuleExports(n *sitter.Node, source []byte) bool {
+	if n == nil || n.Type() != "assignment_expression" {
+		return false
+	}
+	left := n.ChildByFieldName("left")
+	return left != nil && left.Content(source) == "module.exports"
+}
+
+// isExportsMember reports whether n is a property of the CommonJS exports,
+// as in exports.a or module.exports.a.
+func isExportsMember(n *sitter.Node, source []byte) bool {
+	if n.Type() != "member_expression" {
+		return false
+	}
+	object := n.ChildByFieldName("object")
+	if object == nil {
+		return false
+	}
+	text := object.Content(source)
+	return text == "exports" || text == "module.exports"
+}
+
+// cExported reports whether a C or C++ declaration is visible outside its
+// file. Functions marked static outside a class, and anything in an
+// anonymous namespace, are not.
+func cExported(n *sitter.Node, source []byte) bool {
+	for scope := n.Parent(); scope != nil; scope = scope.Parent() {
+		if scope.Type() == "namespace_definition" && scope.ChildByFieldName("name") == nil {
+			return false
+		}
+	}
+	if n.Type() != "function_definition" && n.Type() != "declaration" {
+		return true
+	}
+	scope := n.Parent()
+	if scope != nil && scope.Type() == "template_declaration" {
+		scope = scope.Parent()
+	}
+	if scope == nil || scope.Type() == "field_declaration_list" {
+		// Static members are shared by the class, not hidden.
+		return true
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if spec := n.NamedChild(i); spec.Type() == "storage_class_specifier" && spec.Content(source) == "static" {
+			return false
+		}
+	}
+	return true
+}
+
+// modifierWords returns the keywords in the modifiers of a Java or Kotlin
+// declaration.
+func modifierWords(n *sitter.Node) map[string]bool {
+	words := map[string]bool{}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		mods := n.NamedChild(i)
+		if mods.Type() != "modifiers" {
+			continue
+		}
+		for j := 0; j < int(mods.ChildCount()); j++ {
+			mod := mods.Child(j)
+			if strings.HasSuffix(mod.Type(), "annotation") {
+				continue
+			}
+			// Kotlin wraps each keyword in a *_modifier node.
+			if mod.ChildCount() == 1 {
+				mod = mod.Child(0)
+			}
+			words[mod.Type()] = true
+		}
+	}
+	return words
+}
+
+// rubyPrivate reports whether a Ruby method is private or protected: by a
+// call wrapping the def, as in private def helper, or by the last bare
+// private, protected or public above it in the class body. Methods on self
+// are not affected by a bare private.
+func rubyPrivate(n *sitter.Node, source []byte) bool {
+	if args := n.Parent(); args != nil && args.Type() == "argument_list" {
+		if call := args.Parent(); call != nil && call.Type() == "call" {
+			switch fieldContent(call, "method", source) {
+			case "private", "protected", "private_class_method":
+				return true
+			}
+		}
+	}
+	if n.Type() != "method" {
+		return false
+	}
+	for prev := n.PrevNamedSibling(); prev != nil; prev = prev.PrevNamedSibling() {
+		if prev.Type() != "identifier" {
+			continue
+		}
+		switch prev.Content(source) {
+		case "private", "protected":
+			return true
+		case "public":
+			return false
+		}
+	}
+	return false
+}
+
+func hasVisibility(n *sitter.Node) bool {
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if n.NamedChild(i).Type() == "visibility_modifier" {
+			return true
+		}
+	}
+	return false
+}
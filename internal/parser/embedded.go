@@ -0,0 +1,266 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/html"
+	"github.com/smacker/go-tree-sitter/svelte"
+)
+
+// Region is a part of a host file written in another language, such as the
+// <script> of a Vue component. Its range is in the host file's bytes and
+// lines, and so is everything parsed from it.
+type Region struct {
+	Language string
+	grammar  *sitter.Language
+	sitter.Range
+}
+
+// hostGrammars parse the markup of files whose code is embedded in it.
+// Vue components parse as HTML.
+var hostGrammars = map[string]func() *sitter.Language{
+	"vue":    html.GetLanguage,
+	"svelte": svelte.GetLanguage,
+	"html":   html.GetLanguage,
+}
+
+func hostLanguage(ext string) string {
+	switch ext {
+	case ".vue":
+		return "vue"
+	case ".svelte":
+		return "svelte"
+	case ".html", ".htm":
+		return "html"
+	default:
+		return ""
+	}
+}
+
+// scriptLangs maps the lang attribute of a component's <script> to the
+// extension its code is parsed as.
+var scriptLangs = map[string]string{
+	"js":         ".js",
+	"javascript": ".js",
+	"jsx":        ".js",
+	"ts":         ".ts",
+	"typescript": ".ts",
+	"tsx":        ".tsx",
+}
+
+// scriptTypes does the same for the type attribute of an HTML <script>.
+// Other types, such as application/ld+json or text/template, are data.
+var scriptTypes = map[string]string{
+	"module":                 ".js",
+	"text/javascript":        ".js",
+	"application/javascript": ".js",
+	"text/ecmascript":        ".js",
+	"application/ecmascript": ".js",
+	"text/babel":             ".js",
+	"text/jsx":               ".js",
+	"text/typescript":        ".ts",
+	"application/typescript": ".ts",
+}
+
+// scriptRegions returns the <script> elements of a Vue, Svelte or HTML file
+// holding JavaScript or TypeScript, in order. Scripts loaded with src, and
+// those holding data or in other languages, are left out.
+func scriptRegions(host string, source []byte) ([]Region, error) {
+	parser := sitter.NewParser()
+	parser.SetLanguage(hostGrammars[host]())
+	tree, err := parser.ParseCtx(context.Background(), nil, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+
+	var regions []Region
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if n.Type() != "script_element" {
+			for i := 0; i < int(n.NamedChildCount()); i++ {
+				walk(n.NamedChild(i))
+			}
+			return
+		}
+		code := findChild(n, "raw_text")
+		tag := findChild(n, "start_tag")
+		if code == nil || tag == nil {
+			return
+		}
+		ext, ok := scriptExtension(tag, source)
+		if !ok {
+			return
+		}
+		grammar, language := getLanguage(ext)
+		regions = append(regions, Region{
+			Language: language,
+			grammar:  grammar,
+			Range: sitter.Range{
+				StartPoint: code.StartPoint(),
+				EndPoint:   code.EndPoint(),
+				StartByte:  code.StartByte(),
+				EndByte:    code.EndByte(),
+			},
+		})
+	}
+	walk(tree.RootNode())
+	return regions, nil
+}
+
+// scriptExtension returns the extension the code of the <script> opened by
+// tag is parsed as, from its lang or type attribute, or false if it is not
+// code annotr reads.
+func scriptExtension(tag *sitter.Node, source []byte) (string, bool) {
+	ext := ".js"
+	for i := 0; i < int(tag.NamedChildCount()); i++ {
+		attr := tag.NamedChild(i)
+		if attr.Type() != "attribute" {
+			continue
+		}
+		name := findChild(attr, "attribute_name")
+		if name == nil {
+			continue
+		}
+		value := ""
+		if quoted := findChild(attr, "quoted_attribute_value"); quoted != nil {
+			value = strings.Trim(quoted.Content(source), `"'`)
+		} else if bare := findChild(attr, "attribute_value"); bare != nil {
+			value = bare.Content(source)
+		}
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		var known bool
+		switch strings.ToLower(name.Content(source)) {
+		case "lang":
+			ext, known = scriptLangs[value]
+		case "type":
+			ext, known = scriptTypes[value]
+		case "src":
+			return "", false
+		default:
+			continue
+		}
+		if !known {
+			return "", false
+		}
+	}
+	return ext, true
+}
+
+// newHostParser returns the parser for a Vue, Svelte or HTML file. Its
+// language, which the file is reported as, is that of its scripts: until
+// source is parsed JavaScript, then TypeScript if any of them is. Comments
+// are written for the language of each block's own script; see
+// BlockLanguage.
+func newHostParser(filename, host string) (*Parser, error) {
+	return &Parser{language: "javascript", filename: filename, host: host}, nil
+}
+
+// regionParsers returns a parser for each script region of source, each
+// reading only its own region of the host file, and sets the language of
+// the host from them.
+func (p *Parser) regionParsers(source []byte) ([]*Parser, error) {
+	regions, err := scriptRegions(p.host, source)
+	if err != nil {
+		return nil, err
+	}
+	p.language = "javascript"
+	var parsers []*Parser
+	for _, r := range regions {
+		if r.Language == "typescript" {
+			p.language = "typescript"
+		}
+		query, err := loadQuery(p.filename, r.Language, r.grammar)
+		if err != nil {
+			return nil, err
+		}
+		parser := sitter.NewParser()
+		parser.SetLanguage(r.grammar)
+		parser.SetIncludedRanges([]sitter.Range{r.Range})
+		parsers = append(parsers, &Parser{
+			parser:   parser,
+			language: r.Language,
+			filename: p.filename,
+			grammar:  r.grammar,
+			query:    query,
+			region:   &r,
+		})
+	}
+	return parsers, nil
+}
+
+// parseRegions returns the blocks of every script region in a host file.
+// Blocks starting on the line of a <script> tag are left out, as their
+// comment would land above the tag, in the markup.
+func (p *Parser) parseRegions(source []byte) ([]CodeBlock, error) {
+	parsers, err := p.regionParsers(source)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []CodeBlock
+	for _, sub := range parsers {
+		found, err := sub.Parse(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range found {
+			if !sub.region.sharesTagLine(block.AnchorLine, source) {
+				block.language = sub.language
+				blocks = append(blocks, block)
+			}
+		}
+	}
+	return blocks, nil
+}
+
+// sharesTagLine reports whether row is the line the region starts on and
+// that line also holds the tag opening it.
+func (r *Region) sharesTagLine(row uint32, source []byte) bool {
+	if row != r.StartPoint.Row {
+		return false
+	}
+	lineStart := bytes.LastIndexByte(source[:r.StartByte], '\n') + 1
+	return len(bytes.TrimSpace(source[lineStart:r.StartByte])) > 0
+}
+
+// inlineRegions returns the inline comment candidates of every script
+// region in a host file.
+func (p *Parser) inlineRegions(source []byte, blocks []CodeBlock) ([]CodeBlock, error) {
+	parsers, err := p.regionParsers(source)
+	if err != nil {
+		return nil, err
+	}
+	var inline []CodeBlock
+	for _, sub := range parsers {
+		found, err := sub.ParseInline(source, blocks)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range found {
+			block.language = sub.language
+			inline = append(inline, block)
+		}
+	}
+	return inline, nil
+}
+
+// regionComments returns the comments of every script region in a host file.
+func (p *Parser) regionComments(source []byte) ([]Comment, error) {
+	parsers, err := p.regionParsers(source)
+	if err != nil {
+		return nil, err
+	}
+	var comments []Comment
+	for _, sub := range parsers {
+		found, err := sub.Comments(source)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, found...)
+	}
+	return comments, nil
+}
@@ -0,0 +1,75 @@
+package parser
+
+import "testing"
+
+func TestHostFileRegions(t *testing.T) {
+	source := `<!doctype html>
+<html>
+<head>
+<script type="application/ld+json">{"@type": "Thing"}</script>
+<script src="vendor.js"></script>
+<script lang="ts">
+function typed(n: number): number {
+  return n * 2;
+}
+</script>
+</head>
+<body>
+<script>
+function plain(n) {
+  return n + 1;
+}
+</script>
+</body>
+</html>
+`
+	p, err := NewParser("index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Language() != "javascript" {
+		t.Errorf("Language() before parsing = %q, want javascript", p.Language())
+	}
+
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Language() != "typescript" {
+		t.Errorf("Language() = %q, want typescript", p.Language())
+	}
+	type found struct {
+		name     string
+		line     uint32
+		language string
+	}
+	var got []found
+	for _, b := range blocks {
+		got = append(got, found{b.Name, b.StartLine + 1, p.BlockLanguage(b)})
+	}
+	want := []found{
+		{"typed", 7, "typescript"},
+		{"plain", 14, "javascript"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("blocks = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSvelteScriptOnTagLine(t *testing.T) {
+	source := `<script>function cramped() {}
+export function roomy() {}
+</script>
+
+<p>{roomy()}</p>
+`
+	got := parseBlocks(t, "App.svelte", source)
+	if len(got) != 1 || got[0] != "function roomy@2" {
+		t.Errorf("blocks = %q, want only roomy", got)
+	}
+}
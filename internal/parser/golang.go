@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+)
+
+// GoPackage is the package clause of a Go file.
+type GoPackage struct {
+	Name string
+	Line uint32
+	// DocComment is the package comment above the clause, if any.
+	DocComment string
+}
+
+// Package returns the package clause of a Go file, or nil if it has none.
+func (p *Parser) Package(source []byte) (*GoPackage, error) {
+	if p.language != "go" {
+		return nil, fmt.Errorf("package clauses are only read from Go files")
+	}
+	tree, err := p.parser.ParseCtx(context.Background(), nil, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+
+	root := tree.RootNode()
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		clause := root.NamedChild(i)
+		if clause.Type() != "package_clause" {
+			continue
+		}
+		pkg := &GoPackage{Line: clause.StartPoint().Row}
+		if name := findChild(clause, "package_identifier"); name != nil {
+			pkg.Name = name.Content(source)
+		}
+		doc := indexComments(root, source).docAbove(pkg.Line, source, p.language)
+		pkg.DocComment = doc.text
+		return pkg, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,405 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// DefaultInlineDensity is how many lines of a function each inline comment
+// needs, unless configured otherwise.
+const DefaultInlineDensity = 15
+
+// inlineThreshold is the score a statement needs to be worth a comment.
+const inlineThreshold = 4
+
+// statementContainers hold statements directly; the child of one that
+// contains a candidate is the statement the comment goes above.
+var statementContainers = map[string]bool{
+	"block":              true,
+	"statement_block":    true,
+	"expression_case":    true,
+	"default_case":       true,
+	"type_case":          true,
+	"communication_case": true,
+	"switch_case":        true,
+	"switch_default":     true,
+}
+
+var controlTypes = map[string]bool{
+	"if_statement":                true,
+	"for_statement":               true,
+	"for_in_statement":            true,
+	"while_statement":             true,
+	"do_statement":                true,
+	"try_statement":               true,
+	"with_statement":              true,
+	"switch_statement":            true,
+	"expression_switch_statement": true,
+	"type_switch_statement":       true,
+	"select_statement":            true,
+	"match_statement":             true,
+}
+
+var loopTypes = map[string]bool{
+	"for_statement":    true,
+	"for_in_statement": true,
+	"while_statement":  true,
+	"do_statement":     true,
+}
+
+// functionTypes end the search for what a statement contains; code in a
+// nested function is rated on its own.
+var functionTypes = map[string]bool{
+	"function_declaration": true,
+	"method_declaration":   true,
+	"func_literal":         true,
+	"function_definition":  true,
+	"lambda":               true,
+	"function_expression":  true,
+	"function":             true,
+	"arrow_function":       true,
+	"method_definition":    true,
+}
+
+var booleanOps = map[string]bool{"&&": true, "||": true, "and": true, "or": true}
+
+var bitwiseOps = map[string]bool{"&": true, "|": true, "^": true, "<<": true, ">>": true, ">>>": true, "&^": true}
+
+// ParseInline returns the complex statements inside the function bodies of
+// source that are worth an inline comment, in source order. blocks are the
+// file's blocks as returned by Parse; the Parent of each statement is the
+// innermost function among them that encloses it. Languages without an
+// inline query have no such statements.
+//
+// Each pattern of an inline query captures a candidate as @inline.<kind>,
+// where kind is one inlineScore rates, and for regexes the pattern itself
+// as @pattern. Candidates scoring under inlineThreshold are dropped, and a
+// statement matched by several patterns keeps its highest score.
+func (p *Parser) ParseInline(source []byte, blocks []CodeBlock) ([]CodeBlock, error) {
+	if p.host != "" {
+		return p.inlineRegions(source, blocks)
+	}
+	query, err := loadInlineQuery(p.filename, p.language, p.grammar)
+	if err != nil || query == nil {
+		return nil, err
+	}
+
+	tree, err := p.parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	root := tree.RootNode()
+	comments := indexComments(root, source)
+
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(query, root)
+
+	byStatement := map[[2]uint32]*CodeBlock{}
+	var found []*CodeBlock
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		match = qc.FilterPredicates(match, source)
+
+		var node, pattern *sitter.Node
+		var kind string
+		for _, capture := range match.Captures {
+			name := query.CaptureNameForId(capture.Index)
+			switch {
+			case strings.HasPrefix(name, "inline."):
+				node = capture.Node
+				kind = strings.TrimPrefix(name, "inline.")
+			case name == "pattern":
+				pattern = capture.Node
+			}
+		}
+		if node == nil {
+			continue
+		}
+		stmt := statementOf(node)
+		if stmt == nil {
+			continue
+		}
+		fn := enclosingFunction(blocks, stmt)
+		if fn == nil {
+			continue
+		}
+
+		score := inlineScore(stmt, node, pattern, kind, nestingDepth(stmt, fn), source)
+		if score < inlineThreshold {
+			continue
+		}
+		key := [2]uint32{stmt.StartByte(), stmt.EndByte()}
+		if existing, ok := byStatement[key]; ok {
+			if score > existing.score {
+				existing.score = score
+				existing.Type = kind
+			}
+			continue
+		}
+
+		doc := comments.docAbove(stmt.StartPoint().Row, source, p.language)
+		block := &CodeBlock{
+			Type:         kind,
+			Name:         fn.Name,
+			StartLine:    stmt.StartPoint().Row,
+			EndLine:      stmt.EndPoint().Row,
+			StartByte:    stmt.StartByte(),
+			EndByte:      stmt.EndByte(),
+			Code:         stmt.Content(source),
+			AnchorLine:   stmt.StartPoint().Row,
+			DocComment:   doc.text,
+			DocStartLine: doc.startRow,
+			DocEndLine:   doc.endRow,
+			Parent:       fn,
+			Depth:        fn.Depth + 1,
+			Inline:       true,
+			score:        score,
+		}
+		byStatement[key] = block
+		found = append(found, block)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].StartByte < found[j].StartByte
+	})
+	statements := make([]CodeBlock, len(found))
+	for i, block := range found {
+		statements[i] = *block
+	}
+	if p.language == "php" {
+		statements = outsideTagLines(statements, root)
+	}
+	return statements, nil
+}
+
+// LimitInline keeps at most one inline statement per density lines of each
+// function, at least one, preferring the most complex and skipping any
+// within a couple of lines of one already kept.
+func LimitInline(statements []CodeBlock, density int) []CodeBlock {
+	if density <= 0 {
+		density = DefaultInlineDensity
+	}
+
+	byFunction := map[*CodeBlock][]int{}
+	for i, s := range statements {
+		byFunction[s.Parent] = append(byFunction[s.Parent], i)
+	}
+
+	var kept []int
+	for fn, indices := range byFunction {
+		allowed := int(fn.EndLine-fn.StartLine+1) / density
+		if allowed < 1 {
+			allowed = 1
+		}
+		sort.SliceStable(indices, func(a, b int) bool {
+			return statements[indices[a]].score > statements[indices[b]].score
+		})
+
+		var picked []uint32
+	candidates:
+		for _, i := range indices {
+			if len(picked) == allowed {
+				break
+			}
+			line := statements[i].AnchorLine
+			for _, other := range picked {
+				if line+2 >= other && other+2 >= line {
+					continue candidates
+				}
+			}
+			picked = append(picked, line)
+			kept = append(kept, i)
+		}
+	}
+
+	sort.Ints(kept)
+	var limited []CodeBlock
+	for _, i := range kept {
+		limited = append(limited, statements[i])
+	}
+	return limited
+}
+
+// statementOf returns the statement containing n that sits directly in a
+// block, or nil if n is not inside one.
+func statementOf(n *sitter.Node) *sitter.Node {
+	for parent := n.Parent(); parent != nil; n, parent = parent, parent.Parent() {
+		if statementContainers[parent.Type()] {
+			return n
+		}
+	}
+	return nil
+}
+
+// enclosingFunction returns the innermost function or method in blocks that
+// contains stmt.
+func enclosingFunction(blocks []CodeBlock, stmt *sitter.Node) *CodeBlock {
+	var fn *CodeBlock
+	for i := range blocks {
+		b := &blocks[i]
+		if b.callable && b.StartByte <= stmt.StartByte() && stmt.EndByte() <= b.EndByte {
+			fn = b
+		}
+	}
+	return fn
+}
+
+// nestingDepth counts the control statements around stmt inside fn. An else
+// if continues its chain rather than nesting.
+func nestingDepth(stmt *sitter.Node, fn *CodeBlock) int {
+	depth := 0
+	for n := stmt.Parent(); n != nil && n.StartByte() >= fn.StartByte && n.EndByte() <= fn.EndByte; n = n.Parent() {
+		if !controlTypes[n.Type()] {
+			continue
+		}
+		if parent := n.Parent(); n.Type() == "if_statement" && parent != nil && (parent.Type() == "if_statement" || parent.Type() == "else_clause") {
+			continue
+		}
+		depth++
+	}
+	return depth
+}
+
+// inlineScore rates how much stmt, captured through node as kind, needs
+// explaining. Anything under inlineThreshold is left alone.
+func inlineScore(stmt, node, pattern *sitter.Node, kind string, depth int, source []byte) int {
+	switch kind {
+	case "conditional":
+		if node.Type() != "if_statement" {
+			return countCases(node)/2 + depth
+		}
+		return countOps(node.ChildByFieldName("condition"), booleanOps, source) + ifBranches(node) + depth
+	case "loop":
+		score := int(node.EndPoint().Row-node.StartPoint().Row+1)/6 + depth
+		if containsLoop(node) {
+			score += 3
+		}
+		return score
+	case "error":
+		clauses, statements := handlers(node)
+		return clauses + statements + depth
+	case "regex":
+		if pattern == nil {
+			return 0
+		}
+		meta := 0
+		for _, r := range pattern.Content(source) {
+			if strings.ContainsRune(`\()[]{}*+?|^$.`, r) {
+				meta++
+			}
+		}
+		return meta / 2
+	case "bitwise":
+		return 2 * countOps(stmt, bitwiseOps, source)
+	default:
+		return 0
+	}
+}
+
+// countOps counts the operators from ops in n, leaving out nested blocks
+// and functions so a statement is judged by its own expression.
+func countOps(n *sitter.Node, ops map[string]bool, source []byte) int {
+	if n == nil {
+		return 0
+	}
+	count := 0
+	if op := n.ChildByFieldName("operator"); op != nil && ops[op.Content(source)] {
+		count++
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		if statementContainers[child.Type()] || functionTypes[child.Type()] {
+			continue
+		}
+		count += countOps(child, ops, source)
+	}
+	return count
+}
+
+// ifBranches counts the branches of an if chain, the final else included.
+func ifBranches(n *sitter.Node) int {
+	branches := 1
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		switch child.Type() {
+		case "elif_clause":
+			branches++
+		case "else_clause":
+			if inner := findChild(child, "if_statement"); inner != nil {
+				branches += ifBranches(inner)
+			} else {
+				branches++
+			}
+		}
+	}
+	if alt := n.ChildByFieldName("alternative"); alt != nil {
+		switch alt.Type() {
+		case "if_statement":
+			branches += ifBranches(alt)
+		case "block":
+			branches++
+		}
+	}
+	return branches
+}
+
+// countCases counts the cases of a switch, select or match statement.
+func countCases(n *sitter.Node) int {
+	count := 0
+	for _, parent := range []*sitter.Node{n, n.ChildByFieldName("body")} {
+		if parent == nil {
+			continue
+		}
+		for i := 0; i < int(parent.NamedChildCount()); i++ {
+			if t := parent.NamedChild(i).Type(); strings.HasSuffix(t, "case") || strings.HasSuffix(t, "_default") || t == "case_clause" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func containsLoop(n *sitter.Node) bool {
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		if functionTypes[child.Type()] {
+			continue
+		}
+		if loopTypes[child.Type()] || containsLoop(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// handlers counts the error handling clauses of n, an if err != nil or a
+// try statement, and the statements in them.
+func handlers(n *sitter.Node) (clauses, statements int) {
+	if n.Type() == "if_statement" {
+		if body := n.ChildByFieldName("consequence"); body != nil {
+			statements = countStatements(body)
+		}
+		return 1, statements
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		switch clause := n.NamedChild(i); clause.Type() {
+		case "except_clause", "except_group_clause", "catch_clause", "finally_clause":
+			clauses++
+			body := clause.ChildByFieldName("body")
+			if body == nil {
+				body = findChild(clause, "block")
+			}
+			if body != nil {
+				statements += countStatements(body)
+			}
+		}
+	}
+	return clauses, statements
+}
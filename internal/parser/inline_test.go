@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// parseInline returns the inline statements ParseInline finds in a Go file
+// holding source, keyed by the line they start on.
+func parseInline(t *testing.T, source string) map[uint32]CodeBlock {
+	t.Helper()
+	p, err := NewParser("x.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	statements, err := p.ParseInline([]byte(source), blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byLine := map[uint32]CodeBlock{}
+	for _, s := range statements {
+		byLine[s.AnchorLine+1] = s
+	}
+	return byLine
+}
+
+func TestInlineScore(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		kind string
+		// score is 0 for a statement under inlineThreshold.
+		score int
+	}{
+		{
+			name:  "simple if",
+			body:  "if a {\n\t\treturn\n\t}",
+			score: 0,
+		},
+		{
+			name:  "boolean chain with branches",
+			body:  "if a && b || c {\n\t} else if d {\n\t} else {\n\t}",
+			kind:  "conditional",
+			score: 5,
+		},
+		{
+			name:  "switch",
+			body:  "switch a {\n\tcase 1:\n\tcase 2:\n\tcase 3:\n\tcase 4:\n\tcase 5:\n\tcase 6:\n\tcase 7:\n\tdefault:\n\t}",
+			kind:  "conditional",
+			score: 4,
+		},
+		{
+			name:  "short loop",
+			body:  "for a {\n\t\tb()\n\t}",
+			score: 0,
+		},
+		{
+			name:  "nested loop",
+			body:  "for a {\n\t\tfor b {\n\t\t\tc()\n\t\t}\n\t\td()\n\t\te()\n\t}",
+			kind:  "loop",
+			score: 4,
+		},
+		{
+			name:  "error check with a long handler",
+			body:  "if err != nil {\n\t\tlog(err)\n\t\tcleanup()\n\t\treturn err\n\t}",
+			kind:  "error",
+			score: 4,
+		},
+		{
+			name:  "regex",
+			body:  "re := regexp.MustCompile(`^(\\d+)\\.(\\d+)$`)",
+			kind:  "regex",
+			score: 6,
+		},
+		{
+			name:  "bitwise",
+			body:  "x := a<<3 | b&0xff",
+			kind:  "bitwise",
+			score: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := "package x\n\nfunc f() {\n\t" + tt.body + "\n}\n"
+			s, ok := parseInline(t, source)[4]
+			if tt.score == 0 {
+				if ok {
+					t.Errorf("got %s statement scoring %d, want none", s.Type, s.score)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("no inline statement, want %s scoring %d", tt.kind, tt.score)
+			}
+			if s.Type != tt.kind || s.score != tt.score {
+				t.Errorf("got %s scoring %d, want %s scoring %d", s.Type, s.score, tt.kind, tt.score)
+			}
+		})
+	}
+}
+
+func TestLimitInline(t *testing.T) {
+	short := &CodeBlock{StartLine: 0, EndLine: 9}
+	long := &CodeBlock{StartLine: 20, EndLine: 79}
+	statement := func(fn *CodeBlock, line uint32, score int) CodeBlock {
+		return CodeBlock{AnchorLine: line, Parent: fn, score: score}
+	}
+
+	tests := []struct {
+		name       string
+		statements []CodeBlock
+		density    int
+		want       []uint32
+	}{
+		{
+			name:       "at least one per function",
+			statements: []CodeBlock{statement(short, 2, 4), statement(short, 6, 9)},
+			density:    15,
+			want:       []uint32{6},
+		},
+		{
+			name: "one per density lines, most complex first",
+			statements: []CodeBlock{
+				statement(long, 25, 4), statement(long, 35, 8), statement(long, 45, 5),
+				statement(long, 55, 7), statement(long, 65, 6),
+			},
+			density: 15,
+			want:    []uint32{35, 45, 55, 65},
+		},
+		{
+			name: "skips statements close to one kept",
+			statements: []CodeBlock{
+				statement(long, 30, 9), statement(long, 32, 8), statement(long, 40, 5),
+			},
+			density: 30,
+			want:    []uint32{30, 40},
+		},
+		{
+			name:       "default density",
+			statements: []CodeBlock{statement(long, 25, 4), statement(long, 45, 5), statement(long, 65, 6)},
+			density:    0,
+			want:       []uint32{25, 45, 65},
+		},
+		{
+			name:       "each function has its own allowance",
+			statements: []CodeBlock{statement(short, 2, 4), statement(short, 6, 5), statement(long, 25, 4)},
+			density:    60,
+			want:       []uint32{6, 25},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []uint32
+			for _, s := range LimitInline(tt.statements, tt.density) {
+				got = append(got, s.AnchorLine)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LimitInline() kept lines %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInlineExistingComment(t *testing.T) {
+	source := `package x
+
+func f(a, b, c, d bool) {
+	// Only the first matching case applies.
+	if a && b || c {
+	} else if d {
+	} else {
+	}
+
+	if a && b || c {
+	} else if d {
+	} else {
+	}
+}
+`
+	statements := parseInline(t, source)
+	if s := statements[5]; s.DocComment != "// Only the first matching case applies." || s.DocStartLine != 3 {
+		t.Errorf("commented statement: DocComment = %q at line %d", s.DocComment, s.DocStartLine)
+	}
+	if s, ok := statements[10]; !ok || s.DocComment != "" {
+		t.Errorf("uncommented statement = %+v, %v, want one without a comment", s, ok)
+	}
+}
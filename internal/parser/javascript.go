@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"regexp"
+	"unicode"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+var hookNamePattern = regexp.MustCompile(`^use[A-Z0-9]`)
+
+// componentWrappers are the calls that turn a function into a component
+// without changing what it renders, as in React.memo(() => <div/>).
+var componentWrappers = map[string]bool{
+	"memo":             true,
+	"forwardRef":       true,
+	"React.memo":       true,
+	"React.forwardRef": true,
+}
+
+func isFunctionNode(nodeType string) bool {
+	return nodeType == "arrow_function" || nodeType == "function_expression" || nodeType == "function"
+}
+
+// declaredFunction returns the function bound by a const, let or var
+// declaration, looking through component wrappers, or nil if the
+// declaration binds something else.
+func declaredFunction(decl *sitter.Node, source []byte) *sitter.Node {
+	for i := 0; i < int(decl.NamedChildCount()); i++ {
+		declarator := decl.NamedChild(i)
+		if declarator.Type() != "variable_declarator" {
+			continue
+		}
+		value := declarator.ChildByFieldName("value")
+		if value == nil {
+			continue
+		}
+		if fn := unwrapComponent(value, source); fn != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// unwrapComponent returns value if it is a function, or the function passed
+// to a component wrapper such as memo or forwardRef.
+func unwrapComponent(value *sitter.Node, source []byte) *sitter.Node {
+	if isFunctionNode(value.Type()) {
+		return value
+	}
+	if value.Type() != "call_expression" {
+		return nil
+	}
+	callee := value.ChildByFieldName("function")
+	args := value.ChildByFieldName("arguments")
+	if callee == nil || args == nil || args.NamedChildCount() == 0 || !componentWrappers[callee.Content(source)] {
+		return nil
+	}
+	return unwrapComponent(args.NamedChild(0), source)
+}
+
+// functionExpressionName names a standalone function after the key, field
+// or export it is assigned to.
+func functionExpressionName(n *sitter.Node, source []byte) string {
+	parent := n.Parent()
+	if parent == nil {
+		return ""
+	}
+	switch parent.Type() {
+	case "pair":
+		if key := parent.ChildByFieldName("key"); key != nil {
+			return key.Content(source)
+		}
+	case "field_definition":
+		if property := parent.ChildByFieldName("property"); property != nil {
+			return property.Content(source)
+		}
+	case "public_field_definition":
+		if name := parent.ChildByFieldName("name"); name != nil {
+			return name.Content(source)
+		}
+	case "export_statement":
+		return "default"
+	}
+	return ""
+}
+
+// reactKind classifies a JavaScript or TypeScript block as a React
+// "component" or "hook", or returns "" for anything else.
+func reactKind(n *sitter.Node, name string, source []byte) string {
+	var fn *sitter.Node
+	switch n.Type() {
+	case "function_declaration", "arrow_function", "function_expression", "function":
+		fn = n
+	case "lexical_declaration", "variable_declaration":
+		fn = declaredFunction(n, source)
+	case "class_declaration", "class":
+		if heritage := findChild(n, "class_heritage"); heritage != nil && componentBase.MatchString(heritage.Content(source)) {
+			return "component"
+		}
+		return ""
+	}
+	if fn == nil {
+		return ""
+	}
+
+	if hookNamePattern.MatchString(name) {
+		return "hook"
+	}
+	capitalized := false
+	for _, r := range name {
+		capitalized = unicode.IsUpper(r)
+		break
+	}
+	if (capitalized || name == "default") && containsJSX(fn) {
+		return "component"
+	}
+	if capitalized && fn.Parent().Type() == "arguments" {
+		// Wrapped in memo or forwardRef.
+		return "component"
+	}
+	return ""
+}
+
+var componentBase = regexp.MustCompile(`\b(Pure)?Component\b`)
+
+func containsJSX(n *sitter.Node) bool {
+	switch n.Type() {
+	case "jsx_element", "jsx_self_closing_element", "jsx_fragment":
+		return true
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if containsJSX(n.NamedChild(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+func findChild(n *sitter.Node, nodeType string) *sitter.Node {
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if child := n.NamedChild(i); child.Type() == nodeType {
+			return child
+		}
+	}
+	return nil
+}
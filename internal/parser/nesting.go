@@ -0,0 +1,101 @@
+package parser
+
+import "fmt"
+
+// Nesting policies decide which nested blocks get a comment of their own.
+const (
+	// NestingTop comments top-level blocks only.
+	NestingTop = "top"
+	// NestingMembers also comments the members of classes, modules and
+	// other containers, but not functions declared inside functions.
+	NestingMembers = "members"
+	// NestingAll comments every block, closures included.
+	NestingAll = "all"
+)
+
+var NestingPolicies = []string{NestingTop, NestingMembers, NestingAll}
+
+// ValidateNesting returns an error if policy is not one of NestingPolicies.
+func ValidateNesting(policy string) error {
+	for _, p := range NestingPolicies {
+		if policy == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown nesting policy %q (want top, members or all)", policy)
+}
+
+// callableKinds are the block types whose bodies hold code rather than
+// members, so blocks inside them are local to a call.
+var callableKinds = map[string]bool{
+	"function":    true,
+	"method":      true,
+	"constructor": true,
+	"procedure":   true,
+	"prototype":   true,
+	"hook":        true,
+}
+
+// linkBlocks drops every block whose comment would land on a line already
+// taken by an enclosing or earlier block, then links the rest into a tree.
+// blocks must be ordered outer before inner, as findTargets returns them.
+func linkBlocks(blocks []CodeBlock) []CodeBlock {
+	type slot struct {
+		line  uint32
+		inner bool
+	}
+	taken := map[slot]bool{}
+	kept := blocks[:0]
+	for _, block := range blocks {
+		s := slot{block.AnchorLine, block.InnerDoc}
+		if taken[s] {
+			continue
+		}
+		taken[s] = true
+		kept = append(kept, block)
+	}
+
+	var stack []*CodeBlock
+	for i := range kept {
+		block := &kept[i]
+		for len(stack) > 0 && !contains(stack[len(stack)-1], block) {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			block.Parent = stack[len(stack)-1]
+		}
+		block.Depth = len(stack)
+		stack = append(stack, block)
+	}
+	return kept
+}
+
+func contains(outer, inner *CodeBlock) bool {
+	return outer.StartByte <= inner.StartByte && inner.EndByte <= outer.EndByte
+}
+
+// inCallable reports whether any block enclosing b is a function.
+func (b CodeBlock) inCallable() bool {
+	for parent := b.Parent; parent != nil; parent = parent.Parent {
+		if parent.callable {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectBlocks returns the blocks that policy comments. It assumes policy has
+// passed ValidateNesting; anything else selects every block.
+func SelectBlocks(blocks []CodeBlock, policy string) []CodeBlock {
+	var selected []CodeBlock
+	for _, block := range blocks {
+		switch {
+		case policy == NestingTop && block.Depth > 0:
+			continue
+		case policy == NestingMembers && block.inCallable():
+			continue
+		}
+		selected = append(selected, block)
+	}
+	return selected
+}
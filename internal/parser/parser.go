@@ -4,12 +4,25 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/bash"
+	"github.com/smacker/go-tree-sitter/c"
+	"github.com/smacker/go-tree-sitter/cpp"
+	"github.com/smacker/go-tree-sitter/csharp"
 	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/kotlin"
+	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/ruby"
+	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/sql"
+	"github.com/smacker/go-tree-sitter/swift"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
 )
 
@@ -22,26 +35,98 @@ type CodeBlock struct {
 	EndByte   uint32
 	Code      string
 	Context   string
+	// AnchorLine is the line a doc comment belongs above. It differs from
+	// StartLine when the block is wrapped in decorators or an export.
+	AnchorLine uint32
+	// DocComment is the existing documentation for the block, if any, and
+	// DocStartLine to DocEndLine the lines it occupies.
+	DocComment   string
+	DocStartLine uint32
+	DocEndLine   uint32
+	// InnerDoc is set when the doc comment goes inside the block, on the
+	// line after AnchorLine, like Rust's //! module docs.
+	InnerDoc bool
+	// BodyStart is the first byte of the first statement in a Python body,
+	// where a docstring belongs, or 0 for blocks without one.
+	BodyStart uint32
+	Exported  bool
+	Signature Signature
+	// Parent is the innermost block enclosing this one, nil at the top
+	// level, and Depth the number of blocks enclosing it.
+	Parent *CodeBlock
+	Depth  int
+	// callable is set for functions and methods, whose nested blocks are
+	// local to them rather than members.
+	callable bool
+	// Inline is set for a statement inside a function body, found by
+	// ParseInline, whose comment is a short line comment above it.
+	Inline bool
+	// score rates how much an inline statement needs explaining.
+	score int
+	// language is set for a block in a script of a Vue, Svelte or HTML
+	// file, whose scripts need not share a language.
+	language string
+}
+
+// Body returns the block's code without a doc comment that lives inside it,
+// such as a Python docstring.
+func (b CodeBlock) Body() string {
+	if b.DocComment == "" || b.DocStartLine < b.StartLine || b.DocStartLine > b.EndLine {
+		return b.Code
+	}
+	lines := strings.Split(b.Code, "\n")
+	from := int(b.DocStartLine - b.StartLine)
+	to := int(b.DocEndLine-b.StartLine) + 1
+	if to > len(lines) {
+		to = len(lines)
+	}
+	return strings.Join(append(lines[:from:from], lines[to:]...), "\n")
 }
 
 type Parser struct {
 	parser   *sitter.Parser
 	language string
+	filename string
+	grammar  *sitter.Language
+	query    *sitter.Query
+	// header is set for C and C++ headers, the only files where function
+	// prototypes are documented.
+	header bool
+	// host is set for Vue, Svelte and HTML files, whose scripts are parsed
+	// as regions of the file.
+	host string
+	// region is set for the parser of one such script.
+	region *Region
 }
 
 func NewParser(filename string) (*Parser, error) {
 	ext := strings.ToLower(filepath.Ext(filename))
+	if host := hostLanguage(ext); host != "" {
+		return newHostParser(filename, host)
+	}
 	lang, langName := getLanguage(ext)
+	if lang == nil && ext == "" && isShellScript(filename) {
+		lang, langName = bash.GetLanguage(), "bash"
+	}
 	if lang == nil {
 		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
 
+	query, err := loadQuery(filename, langName, lang)
+	if err != nil {
+		return nil, err
+	}
+
 	parser := sitter.NewParser()
 	parser.SetLanguage(lang)
 
 	return &Parser{
 		parser:   parser,
 		language: langName,
+		filename: filename,
+		grammar:  lang,
+		query:    query,
+		header:   ext == ".h" || ext == ".hpp",
 	}, nil
 }
 
@@ -49,97 +134,323 @@ func (p *Parser) Language() string {
 	return p.language
 }
 
+// BlockLanguage returns the language block is written in, and so its comment
+// for: that of its own script in a Vue, Svelte or HTML file, otherwise the
+// file's.
+func (p *Parser) BlockLanguage(block CodeBlock) string {
+	if block.language != "" {
+		return block.language
+	}
+	return p.language
+}
+
+// Parse returns the blocks selected by the language's query, outer blocks
+// before the blocks they contain and at most one per anchor line. Shell
+// scripts also get a block for their header comment, first. For Vue, Svelte
+// and HTML files, they are the blocks of each script in turn.
 func (p *Parser) Parse(source []byte) ([]CodeBlock, error) {
-	tree, err := p.parser.ParseCtx(context.Background(), nil, source)
+	if p.host != "" {
+		return p.parseRegions(source)
+	}
+	tree, err := p.parse(source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse: %w", err)
 	}
 
+	root := tree.RootNode()
+	comments := indexComments(root, source)
+
 	var blocks []CodeBlock
-	p.extractBlocks(tree.RootNode(), source, &blocks)
+	for _, t := range p.findTargets(root, source) {
+		blocks = append(blocks, p.buildBlock(t, source, comments))
+	}
+	if p.language == "sql" {
+		blocks = sqlBlocks(root, source, comments, blocks)
+	}
+	if p.language == "php" {
+		blocks = outsideTagLines(blocks, root)
+	}
 
+	blocks = linkBlocks(blocks)
+	if p.language == "bash" {
+		if script, ok := p.scriptBlock(root, source); ok && (len(blocks) == 0 || blocks[0].AnchorLine != script.AnchorLine) {
+			blocks = append([]CodeBlock{script}, blocks...)
+		}
+	}
 	return blocks, nil
 }
 
-func (p *Parser) extractBlocks(node *sitter.Node, source []byte, blocks *[]CodeBlock) {
-	nodeType := node.Type()
+// parse parses source into a syntax tree. Template tags in SQL, such as
+// dbt's {{ ref('orders') }}, are masked first so the SQL around them parses.
+func (p *Parser) parse(source []byte) (*sitter.Tree, error) {
+	if p.language == "sql" {
+		source = maskTemplates(source)
+	}
+	return p.parser.ParseCtx(context.Background(), nil, source)
+}
+
+// target is a construct matched by the language's query.
+type target struct {
+	node   *sitter.Node
+	kind   string
+	name   *sitter.Node
+	anchor *sitter.Node
+}
+
+// findTargets runs the query over root and returns one target per matched
+// node, outer nodes before the nodes they contain.
+func (p *Parser) findTargets(root *sitter.Node, source []byte) []*target {
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(p.query, root)
 
-	if p.isCommentableNode(nodeType) {
-		name := p.extractName(node, source)
-		block := CodeBlock{
-			Type:      nodeType,
-			Name:      name,
-			StartLine: node.StartPoint().Row,
-			EndLine:   node.EndPoint().Row,
-			StartByte: node.StartByte(),
-			EndByte:   node.EndByte(),
-			Code:      string(source[node.StartByte():node.EndByte()]),
+	var targets []*target
+	byNode := map[[2]uint32]*target{}
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
 		}
-		*blocks = append(*blocks, block)
-	}
+		match = qc.FilterPredicates(match, source)
 
-	for i := 0; i < int(node.ChildCount()); i++ {
-		child := node.Child(i)
-		p.extractBlocks(child, source, blocks)
+		var t target
+		for _, capture := range match.Captures {
+			name := p.query.CaptureNameForId(capture.Index)
+			switch {
+			case strings.HasPrefix(name, "definition."):
+				t.node = capture.Node
+				t.kind = strings.TrimPrefix(name, "definition.")
+			case name == "name":
+				t.name = capture.Node
+			case name == "anchor":
+				t.anchor = capture.Node
+			}
+		}
+		if t.node == nil || (t.kind == "prototype" && !p.header) {
+			continue
+		}
+
+		// Several patterns may match the same node; merge what they found.
+		key := [2]uint32{t.node.StartByte(), t.node.EndByte()}
+		if existing, ok := byNode[key]; ok && existing.node.Equal(t.node) {
+			if existing.name == nil {
+				existing.name = t.name
+			}
+			if existing.anchor == nil {
+				existing.anchor = t.anchor
+			}
+			continue
+		}
+		byNode[key] = &t
+		targets = append(targets, &t)
 	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		a, b := targets[i].node, targets[j].node
+		if a.StartByte() != b.StartByte() {
+			return a.StartByte() < b.StartByte()
+		}
+		return a.EndByte() > b.EndByte()
+	})
+	return targets
 }
 
-func (p *Parser) isCommentableNode(nodeType string) bool {
-	commentableTypes := map[string]bool{
-		"function_declaration":      true,
-		"method_declaration":        true,
-		"function_definition":       true,
-		"method_definition":         true,
-		"class_definition":          true,
-		"class_declaration":         true,
-		"type_declaration":          true,
-		"interface_declaration":     true,
-		"struct_type":               true,
-		"function":                  true,
-		"arrow_function":            true,
-		"function_expression":       true,
-		"lexical_declaration":       true,
+func (p *Parser) buildBlock(t *target, source []byte, comments commentIndex) CodeBlock {
+	node := t.node
+	name := p.extractName(node, source)
+	if t.name != nil {
+		name = t.name.Content(source)
+	}
+	anchor := t.anchor
+	if anchor == nil {
+		anchor = anchorNode(node)
+	}
+
+	var doc docSpan
+	var bodyStart uint32
+	if p.language == "python" {
+		doc = pythonDocstring(node, source)
+		bodyStart = pythonBodyStart(node)
+	}
+	switch {
+	case doc.text != "":
+	case p.language == "python":
+		// Only a docstring documents Python code, not a # comment above it.
+	case p.language == "rust":
+		doc = comments.rustDocAbove(anchor.StartPoint().Row, source)
+		if doc.text == "" {
+			doc = rustDocAttributes(node, source)
+		}
+	default:
+		doc = comments.docAbove(anchor.StartPoint().Row, source, p.language)
+	}
+	anchorLine := anchor.StartPoint().Row
+	innerDoc := false
+	if doc.text == "" && node.Type() == "mod_item" {
+		if row, ok := rustModuleBody(node); ok {
+			doc = rustInnerDoc(node, source)
+			anchorLine = row
+			innerDoc = true
+		}
+	}
+	blockType := t.kind
+	if p.language == "javascript" || p.language == "typescript" {
+		if kind := reactKind(node, name, source); kind != "" {
+			blockType = kind
+		}
+	}
+
+	return CodeBlock{
+		Type:         blockType,
+		Name:         name,
+		StartLine:    node.StartPoint().Row,
+		EndLine:      node.EndPoint().Row,
+		StartByte:    node.StartByte(),
+		EndByte:      node.EndByte(),
+		Code:         string(source[node.StartByte():node.EndByte()]),
+		AnchorLine:   anchorLine,
+		DocComment:   doc.text,
+		DocStartLine: doc.startRow,
+		DocEndLine:   doc.endRow,
+		InnerDoc:     innerDoc,
+		BodyStart:    bodyStart,
+		Exported:     isExported(node, name, p.language, source),
+		Signature:    parseSignature(node, source, p.language),
+		callable:     callableKinds[t.kind],
 	}
-	return commentableTypes[nodeType]
 }
 
 func (p *Parser) extractName(node *sitter.Node, source []byte) string {
+	if name := node.ChildByFieldName("name"); name != nil {
+		return name.Content(source)
+	}
+	switch node.Type() {
+	case "secondary_constructor":
+		return "constructor"
+	case "arrow_function", "function_expression", "function":
+		return functionExpressionName(node, source)
+	case "statement":
+		// A top-level SQL query, named after its file as dbt names models.
+		return strings.TrimSuffix(filepath.Base(p.filename), filepath.Ext(p.filename))
+	case "lexical_declaration", "variable_declaration":
+		if declarator := findChild(node, "variable_declarator"); declarator != nil {
+			return p.extractName(declarator, source)
+		}
+	}
+	if declarator := node.ChildByFieldName("declarator"); declarator != nil {
+		return declaratorName(declarator, source)
+	}
+	if parent := node.Parent(); parent != nil && parent.Type() == "type_definition" {
+		// typedef struct { ... } name;
+		if declarator := parent.ChildByFieldName("declarator"); declarator != nil {
+			return declaratorName(declarator, source)
+		}
+	}
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
 		childType := child.Type()
-		if childType == "identifier" || childType == "name" || childType == "type_identifier" {
+		if childType == "identifier" || childType == "name" || childType == "type_identifier" || childType == "simple_identifier" {
 			return string(source[child.StartByte():child.EndByte()])
 		}
-		if childType == "function_declarator" || childType == "declarator" {
+		if childType == "type_spec" {
 			return p.extractName(child, source)
 		}
 	}
 	return ""
 }
 
+// declaratorName digs the declared name out of a C or C++ declarator,
+// looking through pointers, references, parentheses and parameter lists.
+// Qualified names such as Foo::bar are kept whole.
+func declaratorName(node *sitter.Node, source []byte) string {
+	switch node.Type() {
+	case "identifier", "field_identifier", "type_identifier", "qualified_identifier",
+		"destructor_name", "operator_name", "template_function":
+		return node.Content(source)
+	}
+	if inner := node.ChildByFieldName("declarator"); inner != nil {
+		return declaratorName(inner, source)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if name := declaratorName(node.NamedChild(i), source); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// functionDeclarator returns the function_declarator of a C or C++
+// function definition or declaration, or nil if it declares something else.
+func functionDeclarator(node *sitter.Node) *sitter.Node {
+	declarator := node.ChildByFieldName("declarator")
+	for declarator != nil {
+		switch declarator.Type() {
+		case "function_declarator":
+			return declarator
+		case "pointer_declarator", "reference_declarator":
+			next := declarator.ChildByFieldName("declarator")
+			if next == nil && declarator.NamedChildCount() > 0 {
+				next = declarator.NamedChild(int(declarator.NamedChildCount()) - 1)
+			}
+			declarator = next
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
 func getLanguage(ext string) (*sitter.Language, string) {
 	switch ext {
 	case ".go":
 		return golang.GetLanguage(), "go"
 	case ".py":
 		return python.GetLanguage(), "python"
-	case ".js":
+	case ".js", ".jsx", ".mjs", ".cjs":
 		return javascript.GetLanguage(), "javascript"
 	case ".ts":
 		return typescript.GetLanguage(), "typescript"
 	case ".tsx":
-		return typescript.GetLanguage(), "typescript"
+		return tsx.GetLanguage(), "typescript"
+	case ".rs":
+		return rust.GetLanguage(), "rust"
+	case ".java":
+		return java.GetLanguage(), "java"
+	case ".kt":
+		return kotlin.GetLanguage(), "kotlin"
+	case ".c":
+		return c.GetLanguage(), "c"
+	case ".h", ".cpp", ".cc", ".hpp":
+		// Headers are parsed as C++, which also reads plain C headers.
+		return cpp.GetLanguage(), "cpp"
+	case ".rb":
+		return ruby.GetLanguage(), "ruby"
+	case ".php":
+		return php.GetLanguage(), "php"
+	case ".sh", ".bash", ".zsh":
+		return bash.GetLanguage(), "bash"
+	case ".cs":
+		return csharp.GetLanguage(), "csharp"
+	case ".swift":
+		return swift.GetLanguage(), "swift"
+	case ".sql":
+		return sql.GetLanguage(), "sql"
 	default:
 		return nil, ""
 	}
 }
 
 func GetSupportedExtensions() []string {
-	return []string{".go", ".py", ".js", ".ts", ".tsx"}
+	return []string{".go", ".py", ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".rs", ".java", ".kt", ".c", ".h", ".cpp", ".cc", ".hpp", ".rb", ".php", ".sh", ".bash", ".zsh", ".cs", ".swift", ".sql", ".vue", ".svelte", ".html", ".htm"}
 }
 
+// IsSupportedFile reports whether annotr can parse filename: by its
+// extension, or for files without one, a shell shebang.
 func IsSupportedFile(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		return isShellScript(filename)
+	}
 	for _, supported := range GetSupportedExtensions() {
 		if ext == supported {
 			return true
@@ -0,0 +1,472 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// parseBlocks parses source as filename and summarises each block as
+// "type name@anchorLine", with 1-based lines.
+func parseBlocks(t *testing.T, filename, source string) []string {
+	t.Helper()
+	p, err := NewParser(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, b := range blocks {
+		got = append(got, fmt.Sprintf("%s %s@%d", b.Type, b.Name, b.AnchorLine+1))
+	}
+	return got
+}
+
+func TestParseGoTypes(t *testing.T) {
+	source := `package shapes
+
+type Point struct {
+	X, Y int
+}
+
+type (
+	Circle struct{ R int }
+	Radius = int
+)
+
+type (
+	Square struct{ Side int }
+)
+`
+	want := []string{
+		"type Point@3",
+		"type Circle@8",
+		"type Radius@9",
+		"type Square@13",
+	}
+	if got := parseBlocks(t, "shapes.go", source); !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %q, want %q", got, want)
+	}
+}
+
+func TestExportedTypeScript(t *testing.T) {
+	source := `export function outer() {
+  function inner() {}
+  const helper = () => 1;
+  return inner;
+}
+
+export const handler = async (req: Request) => {
+  const parse = () => req;
+};
+
+function internal() {}
+
+export class Service {
+  run() {}
+  private reset() {}
+  #secret() {}
+}
+
+class Hidden {
+  run() {}
+}
+
+export default () => 0;
+`
+	p, err := NewParser("api.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, b := range blocks {
+		got[fmt.Sprintf("%s@%d", b.Name, b.StartLine+1)] = b.Exported
+	}
+	want := map[string]bool{
+		"outer@1":     true,
+		"inner@2":     false,
+		"helper@3":    false,
+		"handler@7":   true,
+		"parse@8":     false,
+		"internal@11": false,
+		"Service@13":  true,
+		"run@14":      true,
+		"reset@15":    false,
+		"#secret@16":  false,
+		"Hidden@19":   false,
+		"run@20":      false,
+	}
+	for key, exported := range want {
+		if e, ok := got[key]; !ok {
+			t.Errorf("no block %s in %v", key, got)
+		} else if e != exported {
+			t.Errorf("%s: Exported = %v, want %v", key, e, exported)
+		}
+	}
+}
+
+func TestExportedElsewhere(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		source   string
+		want     map[string]bool
+	}{
+		{
+			name:     "export clause",
+			filename: "api.ts",
+			source: `function listed() {}
+function renamed() {}
+function unlisted() {}
+class Store {
+  get() {}
+}
+const byDefault = () => 0;
+
+export { listed, renamed as other, Store };
+export { unlisted as fromElsewhere } from "./elsewhere";
+export default byDefault;
+`,
+			want: map[string]bool{
+				"listed@1": true, "renamed@2": true, "unlisted@3": false,
+				"Store@4": true, "get@5": true, "byDefault@7": true,
+			},
+		},
+		{
+			name:     "commonjs",
+			filename: "lib.js",
+			source: `function whole() {}
+function shorthand() {}
+function keyed() {}
+function member() {}
+function deepMember() {}
+function hidden() {}
+
+module.exports = whole;
+module.exports = { shorthand, key: keyed, inline: () => hidden() };
+exports.member = member;
+module.exports.deep = deepMember;
+`,
+			want: map[string]bool{
+				"whole@1": true, "shorthand@2": true, "keyed@3": true, "member@4": true,
+				"deepMember@5": true, "hidden@6": false, "inline@9": true,
+			},
+		},
+		{
+			name:     "c static",
+			filename: "util.c",
+			source: `static int helper(int x) { return x; }
+
+int api(int x) { return helper(x); }
+`,
+			want: map[string]bool{"helper@1": false, "api@3": true},
+		},
+		{
+			name:     "cpp static and anonymous namespace",
+			filename: "util.cpp",
+			source: `namespace {
+int hidden() { return 0; }
+}
+
+static int local() { return 1; }
+
+class Counter {
+public:
+  static int count() { return 2; }
+};
+
+int api() { return hidden() + local(); }
+`,
+			want: map[string]bool{
+				"hidden@2": false, "local@5": false, "Counter@7": true, "count@9": true, "api@12": true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(tt.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blocks, err := p.Parse([]byte(tt.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]bool{}
+			for _, b := range blocks {
+				got[fmt.Sprintf("%s@%d", b.Name, b.StartLine+1)] = b.Exported
+			}
+			for key, exported := range tt.want {
+				if e, ok := got[key]; !ok {
+					t.Errorf("no block %s in %v", key, got)
+				} else if e != exported {
+					t.Errorf("%s: Exported = %v, want %v", key, e, exported)
+				}
+			}
+		})
+	}
+}
+
+func TestRustDocComments(t *testing.T) {
+	source := `//! Crate docs.
+
+/// Adds one.
+pub fn add_one(x: i32) -> i32 { x + 1 }
+
+// Just a note.
+pub fn noted() {}
+
+/* Also a note. */
+pub fn block_noted() {}
+
+//// Not a doc either.
+pub fn banner() {}
+
+/** Block doc. */
+pub fn block_doc() {}
+
+/// Doc above a plain comment.
+// TODO: tidy
+pub fn mixed() {}
+
+#[doc = "Attribute doc."]
+#[inline]
+pub fn attr_doc() {}
+
+#[derive(Debug)]
+pub struct Bare;
+`
+	p, err := NewParser("lib.rs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, b := range blocks {
+		got[b.Name] = b.DocComment
+	}
+	want := map[string]string{
+		"add_one":     "/// Adds one.",
+		"noted":       "",
+		"block_noted": "",
+		"banner":      "",
+		"block_doc":   "/** Block doc. */",
+		"mixed":       "/// Doc above a plain comment.",
+		"attr_doc":    `#[doc = "Attribute doc."]`,
+		"Bare":        "",
+	}
+	for name, doc := range want {
+		if got[name] != doc {
+			t.Errorf("%s: DocComment = %q, want %q", name, got[name], doc)
+		}
+	}
+}
+
+func TestParseSamples(t *testing.T) {
+	tests := []struct {
+		file       string
+		blocks     []string
+		documented []string
+	}{
+		{"sample.go", []string{"type Greeter@4", "method Greet@6", "function helper@10"}, []string{"Greeter"}},
+		{"sample.py", []string{"class Store@4", "function get@7", "function _reset@10", "function main@15", "function inner@16"}, []string{"Store"}},
+		{"sample.js", []string{"function add@2", "function double@6", "class Counter@8", "method increment@9", "function fetch@13"}, []string{"add"}},
+		{"sample.ts", []string{"interface Shape@1", "enum Color@5", "class Base@9", "function area@13"}, nil},
+		{"sample.tsx", []string{"component Button@3", "component Card@7", "hook useToggle@9"}, nil},
+		{"sample.rs", []string{"struct Point@4", "enum Shape@8", "trait Area@12", "function area@13", "function new@17", "module inner@22", "function hidden@23"}, []string{"Point"}},
+		{"Sample.java", []string{"class Sample@4", "constructor Sample@7", "method next@11", "interface Listener@15", "method on@16", "enum Mode@19"}, []string{"Sample"}},
+		{"sample.kt", []string{"class Repo@3", "function find@4", "object Registry@7", "function topLevel@9", "interface Source@11", "function load@12", "enum Level@15"}, nil},
+		{"sample.c", []string{"struct point@3", "function add@7", "function main@11"}, nil},
+		{"sample.hpp", []string{"namespace geo@3", "class Shape@5", "function area@7", "prototype scale@10", "function clamp@12"}, nil},
+		{"sample.rb", []string{"module Billing@1", "class Invoice@2", "method total@3", "method secret@9"}, nil},
+		{"sample.php", []string{"interface Payable@5", "method pay@6", "class Order@9", "method pay@10", "function helper@15"}, nil},
+		{"sample.sh", []string{"script sample.sh@2", "function build@5", "function deploy@9"}, []string{"sample.sh"}},
+		{"Sample.cs", []string{"interface IStore@3", "method Get@5", "class Store@8", "property Name@10", "method Get@12", "record Item@15"}, nil},
+		{"sample.swift", []string{"protocol Greeter@3", "method greet@4", "struct Hello@7", "function greet@8", "enum Mood@13"}, nil},
+		{"sample.sql", []string{"table users@1", "view active_users@6", "function user_count@8", "query sample@10"}, nil},
+		{"Sample.vue", []string{"function format@6"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.file)
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := parseBlocks(t, path, string(source)); !reflect.DeepEqual(got, tt.blocks) {
+				t.Errorf("blocks = %q, want %q", got, tt.blocks)
+			}
+
+			p, err := NewParser(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blocks, err := p.Parse(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var documented []string
+			for _, b := range blocks {
+				if b.DocComment != "" {
+					documented = append(documented, b.Name)
+				}
+			}
+			sort.Strings(documented)
+			if !reflect.DeepEqual(documented, tt.documented) {
+				t.Errorf("documented = %q, want %q", documented, tt.documented)
+			}
+		})
+	}
+}
+
+func TestSelectBlocks(t *testing.T) {
+	source := `class Store:
+    def get(self):
+        def decode():
+            pass
+        return decode
+
+
+def main():
+    class Local:
+        pass
+`
+	p, err := NewParser("store.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{NestingTop, []string{"Store", "main"}},
+		{NestingMembers, []string{"Store", "get", "main"}},
+		{NestingAll, []string{"Store", "get", "decode", "main", "Local"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, b := range SelectBlocks(blocks, tt.policy) {
+			got = append(got, b.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SelectBlocks(%s) = %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestSQLCommentOnIsDoc(t *testing.T) {
+	source := `CREATE TABLE public.users (
+    id integer PRIMARY KEY,
+    email text
+);
+-- annotr: generated
+COMMENT ON TABLE users IS 'Registered users.';
+COMMENT ON COLUMN users.id IS 'Key.';
+
+CREATE TABLE orders (id integer);
+
+CREATE TABLE audit.events (id integer);
+
+CREATE VIEW "Active" AS SELECT * FROM users;
+
+CREATE VIEW Recent AS SELECT * FROM users;
+
+CREATE FUNCTION user_count() RETURNS integer AS $$ SELECT count(*) FROM users $$ LANGUAGE sql;
+
+COMMENT ON TABLE public.orders IS 'Orders placed.';
+COMMENT ON TABLE public.events IS 'Not the audit events.';
+COMMENT ON VIEW active IS 'Not "Active", which is quoted.';
+COMMENT ON VIEW recent IS 'Users seen lately.';
+COMMENT ON FUNCTION user_count() IS 'Counts users.';
+`
+	p, err := NewParser("schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"public.users": "-- annotr: generated\nCOMMENT ON TABLE users IS 'Registered users.';\nCOMMENT ON COLUMN users.id IS 'Key.';",
+		"orders":       "COMMENT ON TABLE public.orders IS 'Orders placed.';",
+		"audit.events": "",
+		`"Active"`:     "",
+		"Recent":       "COMMENT ON VIEW recent IS 'Users seen lately.';",
+		"user_count":   "COMMENT ON FUNCTION user_count() IS 'Counts users.';",
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for _, b := range blocks {
+		if b.DocComment != want[b.Name] {
+			t.Errorf("%s: DocComment = %q, want %q", b.Name, b.DocComment, want[b.Name])
+		}
+	}
+}
+
+func TestPHPTagLine(t *testing.T) {
+	source := `<html>
+<?php function cramped() { return 1; } ?>
+<p><?= cramped() ?></p>
+<?php
+function roomy() {}
+`
+	got := parseBlocks(t, "page.php", source)
+	if len(got) != 1 || got[0] != "function roomy@5" {
+		t.Errorf("blocks = %q, want only roomy", got)
+	}
+}
+
+func TestPythonCommentIsNotDocstring(t *testing.T) {
+	source := `# Helpers for orders.
+def total(order):
+    return sum(order.items)
+
+
+# Not a docstring either.
+def tax(order):
+    """Return the tax due on order."""
+    return total(order) * 0.2
+`
+	p, err := NewParser("orders.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"total": "",
+		"tax":   `"""Return the tax due on order."""`,
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), len(want))
+	}
+	for _, b := range blocks {
+		if b.DocComment != want[b.Name] {
+			t.Errorf("%s: DocComment = %q, want %q", b.Name, b.DocComment, want[b.Name])
+		}
+	}
+}
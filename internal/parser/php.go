@@ -0,0 +1,35 @@
+package parser
+
+import sitter "github.com/smacker/go-tree-sitter"
+
+// phpTagRows returns the lines of a PHP file holding a <?php or <?= tag.
+// The tags sit at the top level, or in the markup between two blocks of
+// code.
+func phpTagRows(root *sitter.Node) map[uint32]bool {
+	rows := map[uint32]bool{}
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		child := root.NamedChild(i)
+		switch child.Type() {
+		case "php_tag":
+			rows[child.StartPoint().Row] = true
+		case "text_interpolation":
+			if tag := findChild(child, "php_tag"); tag != nil {
+				rows[tag.StartPoint().Row] = true
+			}
+		}
+	}
+	return rows
+}
+
+// outsideTagLines leaves out the blocks starting on the line of a PHP tag,
+// as their comment would land above the tag, in the markup.
+func outsideTagLines(blocks []CodeBlock, root *sitter.Node) []CodeBlock {
+	rows := phpTagRows(root)
+	kept := blocks[:0]
+	for _, block := range blocks {
+		if !rows[block.AnchorLine] {
+			kept = append(kept, block)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// The built-in queries select which constructs annotr documents in each
+// language, and those in queries/inline the statements considered for
+// inline comments. A file with the same name in a project's .annotr/queries
+// directory, or in ~/.annotr/queries, replaces the built-in one.
+//
+//go:embed queries/*.scm queries/inline/*.scm
+var builtinQueries embed.FS
+
+var (
+	queryCacheMu sync.Mutex
+	queryCache   = map[queryKey]*sitter.Query{}
+)
+
+type queryKey struct {
+	path    string
+	grammar *sitter.Language
+}
+
+// loadQuery returns the compiled query for the language of filename,
+// preferring an override found near the file or in the user's home.
+func loadQuery(filename, language string, grammar *sitter.Language) (*sitter.Query, error) {
+	path := findQueryOverride(filename, language)
+	builtin := path == ""
+	if builtin {
+		path = "queries/" + language + ".scm"
+	}
+
+	queryCacheMu.Lock()
+	defer queryCacheMu.Unlock()
+
+	key := queryKey{path: path, grammar: grammar}
+	if q, ok := queryCache[key]; ok {
+		return q, nil
+	}
+
+	var data []byte
+	var err error
+	if builtin {
+		data, err = builtinQueries.ReadFile(path)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query %s: %w", path, err)
+	}
+
+	q, err := sitter.NewQuery(data, grammar)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %s: %w", path, err)
+	}
+	queryCache[key] = q
+	return q, nil
+}
+
+// loadInlineQuery returns the query selecting inline comment candidates for
+// the language of filename, or nil if there is none for that language.
+func loadInlineQuery(filename, language string, grammar *sitter.Language) (*sitter.Query, error) {
+	name := "inline/" + language
+	if findQueryOverride(filename, name) == "" {
+		if _, err := fs.Stat(builtinQueries, "queries/"+name+".scm"); err != nil {
+			return nil, nil
+		}
+	}
+	return loadQuery(filename, name, grammar)
+}
+
+// findQueryOverride looks for <language>.scm in .annotr/queries in the
+// directory of filename and each of its parents, then in ~/.annotr/queries.
+// It returns "" when the built-in query applies.
+func findQueryOverride(filename, language string) string {
+	name := language + ".scm"
+
+	if abs, err := filepath.Abs(filename); err == nil {
+		for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+			candidate := filepath.Join(dir, ".annotr", "queries", name)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate
+			}
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		candidate := filepath.Join(home, ".annotr", "queries", name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
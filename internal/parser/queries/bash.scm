@@ -0,0 +1,5 @@
+; Blocks annotr documents in shell scripts. The header comment describing
+; the whole script is added by the parser, not matched here.
+
+(function_definition
+  name: (word) @name) @definition.function
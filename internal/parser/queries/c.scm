@@ -0,0 +1,14 @@
+; Blocks annotr documents in C source files. Names of functions come from
+; their declarators, so no @name is needed.
+
+(function_definition) @definition.function
+
+; Type definitions, but not the same specifiers used as types.
+(struct_specifier
+  body: (_)) @definition.struct
+
+(union_specifier
+  body: (_)) @definition.union
+
+(enum_specifier
+  body: (_)) @definition.enum
@@ -0,0 +1,46 @@
+; Blocks annotr documents in C++ and in C and C++ headers. Templates are
+; documented above the template line without needing an @anchor.
+
+(function_definition) @definition.function
+
+; Type definitions, but not the same specifiers used as types.
+(struct_specifier
+  body: (_)) @definition.struct
+
+(union_specifier
+  body: (_)) @definition.union
+
+(enum_specifier
+  body: (_)) @definition.enum
+
+(class_specifier
+  body: (_)) @definition.class
+
+(namespace_definition
+  name: (_) @name) @definition.namespace
+
+; Function prototypes, which annotr only documents in headers. Function
+; pointer variables have a parenthesized declarator and don't match.
+(declaration
+  declarator: [
+    (function_declarator
+      declarator: [(identifier) (qualified_identifier) (destructor_name) (operator_name)])
+    (pointer_declarator
+      declarator: (function_declarator
+        declarator: [(identifier) (qualified_identifier) (operator_name)]))
+    (reference_declarator
+      (function_declarator
+        declarator: [(identifier) (qualified_identifier) (operator_name)]))
+  ]) @definition.prototype
+
+(field_declaration
+  declarator: [
+    (function_declarator
+      declarator: [(field_identifier) (destructor_name) (operator_name)])
+    (pointer_declarator
+      declarator: (function_declarator
+        declarator: [(field_identifier) (operator_name)]))
+    (reference_declarator
+      (function_declarator
+        declarator: [(field_identifier) (operator_name)]))
+  ]) @definition.prototype
@@ -0,0 +1,32 @@
+; Blocks annotr documents in C#. Attributes are part of the declaration, so
+; the comment already goes above them.
+
+(class_declaration
+  name: (identifier) @name) @definition.class
+
+(struct_declaration
+  name: (identifier) @name) @definition.struct
+
+(record_declaration
+  name: (identifier) @name) @definition.record
+
+(interface_declaration
+  name: (identifier) @name) @definition.interface
+
+(enum_declaration
+  name: (identifier) @name) @definition.enum
+
+(delegate_declaration
+  name: (identifier) @name) @definition.delegate
+
+(method_declaration
+  name: (identifier) @name) @definition.method
+
+(constructor_declaration
+  name: (identifier) @name) @definition.constructor
+
+(property_declaration
+  name: (identifier) @name) @definition.property
+
+(local_function_statement
+  name: (identifier) @name) @definition.function
@@ -0,0 +1,28 @@
+; Blocks annotr documents in Go.
+;
+; Each pattern captures the declaration as @definition.<kind>, and
+; optionally its identifier as @name and the node the comment goes above as
+; @anchor.
+
+(function_declaration
+  name: (identifier) @name) @definition.function
+
+(method_declaration
+  name: (field_identifier) @name) @definition.method
+
+; A lone type is documented above its declaration, each type in a
+; type ( ... ) group above its own spec. Anchors skip the anonymous "(",
+; so the predicate is what keeps groups out of the first pattern.
+((type_declaration
+  [(type_spec
+     name: (type_identifier) @name)
+   (type_alias
+     name: (type_identifier) @name)]) @definition.type
+  (#not-match? @definition.type "^type\\s*\\("))
+
+(type_declaration
+  "("
+  [(type_spec
+     name: (type_identifier) @name)
+   (type_alias
+     name: (type_identifier) @name)] @definition.type)
@@ -0,0 +1,25 @@
+; Statements inside Go function bodies that may deserve an inline comment.
+
+(if_statement) @inline.conditional
+(expression_switch_statement) @inline.conditional
+(type_switch_statement) @inline.conditional
+(select_statement) @inline.conditional
+
+(for_statement) @inline.loop
+
+(if_statement
+  condition: (binary_expression
+    left: (identifier) @_err
+    right: (nil))
+  (#eq? @_err "err")) @inline.error
+
+(call_expression
+  function: (selector_expression
+    operand: (identifier) @_pkg)
+  arguments: (argument_list
+    .
+    [(raw_string_literal) (interpreted_string_literal)] @pattern)
+  (#eq? @_pkg "regexp")) @inline.regex
+
+(binary_expression
+  operator: ["&" "|" "^" "<<" ">>" "&^"]) @inline.bitwise
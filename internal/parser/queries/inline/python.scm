@@ -0,0 +1,21 @@
+; Statements inside Python function bodies that may deserve an inline
+; comment.
+
+(if_statement) @inline.conditional
+(match_statement) @inline.conditional
+
+(for_statement) @inline.loop
+(while_statement) @inline.loop
+
+(try_statement) @inline.error
+
+(call
+  function: (attribute
+    object: (identifier) @_mod)
+  arguments: (argument_list
+    .
+    (string) @pattern)
+  (#eq? @_mod "re")) @inline.regex
+
+(binary_operator
+  operator: ["&" "|" "^" "<<" ">>"]) @inline.bitwise
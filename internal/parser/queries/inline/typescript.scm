@@ -0,0 +1,25 @@
+; Statements inside TypeScript function bodies that may deserve an inline
+; comment.
+
+(if_statement) @inline.conditional
+(switch_statement) @inline.conditional
+
+(for_statement) @inline.loop
+(for_in_statement) @inline.loop
+(while_statement) @inline.loop
+(do_statement) @inline.loop
+
+(try_statement) @inline.error
+
+(regex
+  pattern: (regex_pattern) @pattern) @inline.regex
+
+(new_expression
+  constructor: (identifier) @_ctor
+  arguments: (arguments
+    .
+    [(string) (template_string)] @pattern)
+  (#eq? @_ctor "RegExp")) @inline.regex
+
+(binary_expression
+  operator: ["&" "|" "^" "<<" ">>" ">>>"]) @inline.bitwise
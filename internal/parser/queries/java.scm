@@ -0,0 +1,23 @@
+; Blocks annotr documents in Java. Annotations are part of the declaration,
+; so the comment already goes above them.
+
+(class_declaration
+  name: (identifier) @name) @definition.class
+
+(interface_declaration
+  name: (identifier) @name) @definition.interface
+
+(enum_declaration
+  name: (identifier) @name) @definition.enum
+
+(record_declaration
+  name: (identifier) @name) @definition.record
+
+(annotation_type_declaration
+  name: (identifier) @name) @definition.annotation
+
+(method_declaration
+  name: (identifier) @name) @definition.method
+
+(constructor_declaration
+  name: (identifier) @name) @definition.constructor
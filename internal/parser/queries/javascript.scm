@@ -0,0 +1,46 @@
+; Blocks annotr documents in JavaScript.
+
+(function_declaration
+  name: (identifier) @name) @definition.function
+
+(generator_function_declaration
+  name: (identifier) @name) @definition.function
+
+(class_declaration
+  name: (identifier) @name) @definition.class
+
+(method_definition
+  name: (_) @name) @definition.method
+
+; Functions bound by const, let or var.
+(lexical_declaration
+  (variable_declarator
+    name: (identifier) @name
+    value: [(arrow_function) (function_expression)])) @definition.function
+
+(variable_declaration
+  (variable_declarator
+    name: (identifier) @name
+    value: [(arrow_function) (function_expression)])) @definition.function
+
+; Components wrapped in memo or forwardRef.
+((lexical_declaration
+  (variable_declarator
+    name: (identifier) @name
+    value: (call_expression
+      function: (_) @_wrapper
+      arguments: (arguments . [(arrow_function) (function_expression)])))) @definition.function
+  (#match? @_wrapper "^(React\\.)?(memo|forwardRef)$"))
+
+; Functions assigned to object keys and class fields, and default exports.
+; Inline callbacks are left alone.
+(pair
+  key: (_) @name
+  value: [(arrow_function) (function_expression)] @definition.function)
+
+(field_definition
+  property: (_) @name
+  value: [(arrow_function) (function_expression)] @definition.function)
+
+(export_statement
+  value: [(arrow_function) (function_expression)] @definition.function)
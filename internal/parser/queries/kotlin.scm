@@ -0,0 +1,23 @@
+; Blocks annotr documents in Kotlin. The grammar has no field names, so
+; names are matched as direct children. Interfaces and enum classes are
+; class_declarations too, told apart by their keyword; the first pattern
+; matching a declaration sets its kind.
+
+(class_declaration
+  "interface"
+  (type_identifier) @name) @definition.interface
+
+(class_declaration
+  "enum"
+  (type_identifier) @name) @definition.enum
+
+(class_declaration
+  (type_identifier) @name) @definition.class
+
+(object_declaration
+  (type_identifier) @name) @definition.object
+
+(function_declaration
+  (simple_identifier) @name) @definition.function
+
+(secondary_constructor) @definition.constructor
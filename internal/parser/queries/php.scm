@@ -0,0 +1,20 @@
+; Blocks annotr documents in PHP. Attributes are part of the declaration,
+; so the comment already goes above them.
+
+(function_definition
+  name: (name) @name) @definition.function
+
+(class_declaration
+  name: (name) @name) @definition.class
+
+(interface_declaration
+  name: (name) @name) @definition.interface
+
+(trait_declaration
+  name: (name) @name) @definition.trait
+
+(enum_declaration
+  name: (name) @name) @definition.enum
+
+(method_declaration
+  name: (name) @name) @definition.method
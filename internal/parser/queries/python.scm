@@ -0,0 +1,16 @@
+; Blocks annotr documents in Python.
+
+(function_definition
+  name: (identifier) @name) @definition.function
+
+(class_definition
+  name: (identifier) @name) @definition.class
+
+; Decorated definitions are documented above their decorators.
+(decorated_definition
+  definition: (function_definition
+    name: (identifier) @name) @definition.function) @anchor
+
+(decorated_definition
+  definition: (class_definition
+    name: (identifier) @name) @definition.class) @anchor
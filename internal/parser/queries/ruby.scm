@@ -0,0 +1,22 @@
+; Blocks annotr documents in Ruby.
+
+(module
+  name: (_) @name) @definition.module
+
+(class
+  name: (_) @name) @definition.class
+
+(method
+  name: (_) @name) @definition.method
+
+(singleton_method
+  name: (_) @name) @definition.method
+
+; A method defined inline with its visibility, as in private def helper,
+; is documented above the whole call.
+((call
+  method: (identifier) @_visibility
+  arguments: (argument_list
+    .
+    [(method name: (_) @name) (singleton_method name: (_) @name)] @definition.method)) @anchor
+  (#match? @_visibility "^(private|protected|public|module_function|private_class_method)$"))
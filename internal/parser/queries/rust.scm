@@ -0,0 +1,20 @@
+; Blocks annotr documents in Rust. Attributes such as #[derive] are kept
+; below the comment without needing an @anchor.
+
+(function_item
+  name: (identifier) @name) @definition.function
+
+(function_signature_item
+  name: (identifier) @name) @definition.function
+
+(struct_item
+  name: (type_identifier) @name) @definition.struct
+
+(enum_item
+  name: (type_identifier) @name) @definition.enum
+
+(trait_item
+  name: (type_identifier) @name) @definition.trait
+
+(mod_item
+  name: (identifier) @name) @definition.module
@@ -0,0 +1,19 @@
+; Blocks annotr documents in SQL. Procedures, and functions written in
+; dialects the grammar cannot parse, are recovered by the parser instead.
+
+(create_table
+  (object_reference) @name) @definition.table
+
+(create_view
+  (object_reference) @name) @definition.view
+
+(create_materialized_view
+  (object_reference) @name) @definition.materialized_view
+
+(create_function
+  (object_reference) @name) @definition.function
+
+; Top-level queries built from common table expressions, as in dbt models.
+(program
+  (statement
+    (keyword_with)) @definition.query)
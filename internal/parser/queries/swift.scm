@@ -0,0 +1,48 @@
+; Blocks annotr documents in Swift. Classes, structs, enums, actors and
+; extensions are all class_declarations, told apart by their keyword.
+; Attributes such as @MainActor are part of the declaration, so the
+; comment already goes above them.
+
+(class_declaration
+  declaration_kind: "class"
+  name: (type_identifier) @name) @definition.class
+
+(class_declaration
+  declaration_kind: "struct"
+  name: (type_identifier) @name) @definition.struct
+
+(class_declaration
+  declaration_kind: "enum"
+  name: (type_identifier) @name) @definition.enum
+
+(class_declaration
+  declaration_kind: "actor"
+  name: (type_identifier) @name) @definition.actor
+
+(class_declaration
+  declaration_kind: "extension"
+  name: (user_type) @name) @definition.extension
+
+(protocol_declaration
+  name: (type_identifier) @name) @definition.protocol
+
+; The return type is also under name:, so match the identifier itself;
+; operators such as == have no identifier and are named by the parser.
+(function_declaration
+  name: (simple_identifier) @name) @definition.function
+
+(function_declaration) @definition.function
+
+(protocol_function_declaration
+  name: (simple_identifier) @name) @definition.method
+
+(init_declaration) @definition.constructor
+
+; Stored and computed properties of types, not local variables.
+(class_body
+  (property_declaration
+    name: (pattern) @name) @definition.property)
+
+(protocol_body
+  (protocol_property_declaration
+    name: (pattern) @name) @definition.property)
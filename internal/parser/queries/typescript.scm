@@ -0,0 +1,55 @@
+; Blocks annotr documents in TypeScript and TSX.
+
+(function_declaration
+  name: (identifier) @name) @definition.function
+
+(generator_function_declaration
+  name: (identifier) @name) @definition.function
+
+(class_declaration
+  name: (type_identifier) @name) @definition.class
+
+(abstract_class_declaration
+  name: (type_identifier) @name) @definition.class
+
+(interface_declaration
+  name: (type_identifier) @name) @definition.interface
+
+(enum_declaration
+  name: (identifier) @name) @definition.enum
+
+(method_definition
+  name: (_) @name) @definition.method
+
+; Functions bound by const, let or var.
+(lexical_declaration
+  (variable_declarator
+    name: (identifier) @name
+    value: [(arrow_function) (function_expression)])) @definition.function
+
+(variable_declaration
+  (variable_declarator
+    name: (identifier) @name
+    value: [(arrow_function) (function_expression)])) @definition.function
+
+; Components wrapped in memo or forwardRef.
+((lexical_declaration
+  (variable_declarator
+    name: (identifier) @name
+    value: (call_expression
+      function: (_) @_wrapper
+      arguments: (arguments . [(arrow_function) (function_expression)])))) @definition.function
+  (#match? @_wrapper "^(React\\.)?(memo|forwardRef)$"))
+
+; Functions assigned to object keys and class fields, and default exports.
+; Inline callbacks are left alone.
+(pair
+  key: (_) @name
+  value: [(arrow_function) (function_expression)] @definition.function)
+
+(public_field_definition
+  name: (_) @name
+  value: [(arrow_function) (function_expression)] @definition.function)
+
+(export_statement
+  value: [(arrow_function) (function_expression)] @definition.function)
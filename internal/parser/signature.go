@@ -0,0 +1,874 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Signature is the part of a declaration that doc comment tags describe.
+type Signature struct {
+	Params []Param
+	// Returns is set when the function gives back a value, and ReturnType
+	// is its declared type, empty when the code leaves it out.
+	Returns    bool
+	ReturnType string
+	Throws     []string
+	// Env lists the environment variables a shell script reads.
+	Env []string
+	// Columns lists the columns of a SQL table.
+	Columns []Param
+}
+
+type Param struct {
+	Name string
+	// Type is the declared type, if any.
+	Type string
+	// Optional is set for parameters with a default value.
+	Optional bool
+}
+
+// setReturn records a declared return type unless it is one of the types
+// that mean nothing is returned.
+func (s *Signature) setReturn(returnType string, none ...string) {
+	for _, n := range none {
+		if returnType == n {
+			return
+		}
+	}
+	s.Returns = true
+	s.ReturnType = returnType
+}
+
+func parseSignature(n *sitter.Node, source []byte, language string) Signature {
+	switch language {
+	case "go":
+		return goSignature(n, source)
+	case "python":
+		return pythonSignature(n, source)
+	case "javascript", "typescript":
+		return jsSignature(n, source)
+	case "java":
+		return javaSignature(n, source)
+	case "kotlin":
+		return kotlinSignature(n, source)
+	case "c", "cpp":
+		return cSignature(n, source)
+	case "ruby":
+		return rubySignature(n, source)
+	case "php":
+		return phpSignature(n, source)
+	case "csharp":
+		return csharpSignature(n, source)
+	case "swift":
+		return swiftSignature(n, source)
+	case "sql":
+		return sqlSignature(n, source)
+	default:
+		return Signature{}
+	}
+}
+
+func javaSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	switch n.Type() {
+	case "method_declaration", "constructor_declaration", "record_declaration":
+	default:
+		return sig
+	}
+
+	if params := n.ChildByFieldName("parameters"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			param := params.NamedChild(i)
+			switch param.Type() {
+			case "formal_parameter":
+				if name := param.ChildByFieldName("name"); name != nil {
+					sig.Params = append(sig.Params, Param{Name: name.Content(source), Type: fieldContent(param, "type", source)})
+				}
+			case "spread_parameter":
+				// String... args has no type field; the type is the child
+				// before the declarator.
+				var p Param
+				for j := 0; j < int(param.NamedChildCount()); j++ {
+					switch part := param.NamedChild(j); part.Type() {
+					case "modifiers":
+					case "variable_declarator":
+						p.Name = fieldContent(part, "name", source)
+					default:
+						if p.Type == "" {
+							p.Type = part.Content(source) + "..."
+						}
+					}
+				}
+				if p.Name != "" {
+					sig.Params = append(sig.Params, p)
+				}
+			}
+		}
+	}
+
+	if n.Type() == "method_declaration" {
+		if ret := n.ChildByFieldName("type"); ret != nil && ret.Type() != "void_type" {
+			sig.setReturn(ret.Content(source))
+		}
+	}
+
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if throws := n.NamedChild(i); throws.Type() == "throws" {
+			for j := 0; j < int(throws.NamedChildCount()); j++ {
+				sig.Throws = append(sig.Throws, throws.NamedChild(j).Content(source))
+			}
+		}
+	}
+
+	return sig
+}
+
+func kotlinSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	switch n.Type() {
+	case "function_declaration", "secondary_constructor":
+	default:
+		return sig
+	}
+
+	afterParams := false
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		switch {
+		case child.Type() == "modifiers":
+			sig.Throws = kotlinThrows(child, source)
+		case child.Type() == "function_value_parameters":
+			last := -1
+			for j := 0; j < int(child.ChildCount()); j++ {
+				param := child.Child(j)
+				if param.Type() == "=" && last >= 0 {
+					// The default value follows the parameter it belongs to.
+					sig.Params[last].Optional = true
+					continue
+				}
+				if param.Type() != "parameter" {
+					continue
+				}
+				last = -1
+				var p Param
+				for k := 0; k < int(param.NamedChildCount()); k++ {
+					switch part := param.NamedChild(k); {
+					case part.Type() == "simple_identifier" && p.Name == "":
+						p.Name = part.Content(source)
+					case strings.HasSuffix(part.Type(), "_type"):
+						p.Type = part.Content(source)
+					}
+				}
+				if p.Name != "" {
+					sig.Params = append(sig.Params, p)
+					last = len(sig.Params) - 1
+				}
+			}
+			afterParams = true
+		case afterParams && strings.HasSuffix(child.Type(), "_type"):
+			// An explicit return type; expression bodies without one are
+			// left undocumented rather than guessed.
+			sig.setReturn(child.Content(source), "Unit")
+			afterParams = false
+		}
+	}
+
+	return sig
+}
+
+// kotlinThrows reads the exception classes from a @Throws(...) annotation.
+func kotlinThrows(modifiers *sitter.Node, source []byte) []string {
+	var throws []string
+	for i := 0; i < int(modifiers.NamedChildCount()); i++ {
+		annotation := modifiers.NamedChild(i)
+		if annotation.Type() != "annotation" || annotation.NamedChildCount() == 0 {
+			continue
+		}
+		call := annotation.NamedChild(0)
+		if call.Type() != "constructor_invocation" || call.NamedChildCount() < 2 {
+			continue
+		}
+		if call.NamedChild(0).Content(source) != "Throws" {
+			continue
+		}
+		args := call.NamedChild(1)
+		for j := 0; j < int(args.NamedChildCount()); j++ {
+			arg := strings.TrimSuffix(args.NamedChild(j).Content(source), "::class")
+			throws = append(throws, arg)
+		}
+	}
+	return throws
+}
+
+func cSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	declarator := functionDeclarator(n)
+	if declarator == nil {
+		return sig
+	}
+
+	if params := declarator.ChildByFieldName("parameters"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			param := params.NamedChild(i)
+			if param.Type() != "parameter_declaration" && param.Type() != "optional_parameter_declaration" {
+				continue
+			}
+			// Unnamed parameters, including (void), have no declarator.
+			if d := param.ChildByFieldName("declarator"); d != nil {
+				if name := declaratorName(d, source); name != "" {
+					sig.Params = append(sig.Params, Param{Name: name, Type: cParamType(param, d, source)})
+				}
+			}
+		}
+	}
+
+	// Constructors and destructors have no return type.
+	if ret := n.ChildByFieldName("type"); ret != nil {
+		returns := ret.Content(source)
+		if outer := n.ChildByFieldName("declarator"); outer.Type() != "function_declarator" {
+			// A pointer or reference declarator wraps the function.
+			returns += " " + strings.TrimSpace(outer.Content(source)[:declarator.StartByte()-outer.StartByte()])
+		}
+		sig.setReturn(returns, "void")
+	}
+
+	return sig
+}
+
+// cParamType rebuilds a C or C++ parameter's type from its type specifier and
+// the pointer, reference and array declarators around its name, so that
+// char *buf is documented as char *, not char.
+func cParamType(param, declarator *sitter.Node, source []byte) string {
+	var qualifiers []string
+	for i := 0; i < int(param.NamedChildCount()); i++ {
+		if q := param.NamedChild(i); q.Type() == "type_qualifier" && q.StartByte() < declarator.StartByte() {
+			qualifiers = append(qualifiers, q.Content(source))
+		}
+	}
+	base := strings.Join(append(qualifiers, fieldContent(param, "type", source)), " ")
+
+	var pointers, arrays string
+	for d := declarator; d != nil; {
+		switch d.Type() {
+		case "pointer_declarator":
+			pointers += "*"
+			for i := 0; i < int(d.NamedChildCount()); i++ {
+				if q := d.NamedChild(i); q.Type() == "type_qualifier" {
+					pointers += q.Content(source) + " "
+				}
+			}
+			d = d.ChildByFieldName("declarator")
+		case "reference_declarator":
+			pointers += d.Child(0).Content(source)
+			d = d.NamedChild(int(d.NamedChildCount()) - 1)
+		case "array_declarator":
+			// The outermost declarator is the last dimension.
+			arrays = "[" + fieldContent(d, "size", source) + "]" + arrays
+			d = d.ChildByFieldName("declarator")
+		case "parenthesized_declarator", "function_declarator":
+			// Function pointers and the like: the declaration minus its
+			// name is the type.
+			return cAbstractType(param, declarator, source)
+		default:
+			d = nil
+		}
+	}
+
+	if pointers != "" {
+		base += " " + strings.TrimSpace(pointers)
+	}
+	return base + arrays
+}
+
+// cAbstractType returns param's declaration with the name, and any default
+// value, cut out: int (*cb)(int) becomes int (*)(int).
+func cAbstractType(param, declarator *sitter.Node, source []byte) string {
+	end := param.EndByte()
+	if value := param.ChildByFieldName("default_value"); value != nil {
+		end = value.StartByte()
+	}
+	text := string(source[param.StartByte():end])
+	if name := declaratorIdentifier(declarator); name != nil {
+		from, to := name.StartByte()-param.StartByte(), name.EndByte()-param.StartByte()
+		text = text[:from] + text[to:]
+	}
+	text = strings.TrimSuffix(strings.TrimSpace(text), "=")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// declaratorIdentifier returns the identifier a C or C++ declarator
+// declares, or nil for an abstract one.
+func declaratorIdentifier(n *sitter.Node) *sitter.Node {
+	if n.Type() == "identifier" {
+		return n
+	}
+	if inner := n.ChildByFieldName("declarator"); inner != nil {
+		return declaratorIdentifier(inner)
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if child := n.NamedChild(i); child.Type() != "parameter_list" {
+			if id := declaratorIdentifier(child); id != nil {
+				return id
+			}
+		}
+	}
+	return nil
+}
+
+func goSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	switch n.Type() {
+	case "function_declaration", "method_declaration":
+	default:
+		return sig
+	}
+
+	if params := n.ChildByFieldName("parameters"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			param := params.NamedChild(i)
+			typ := fieldContent(param, "type", source)
+			if param.Type() == "variadic_parameter_declaration" {
+				typ = "..." + typ
+			}
+			// a, b int declares two parameters of one type.
+			for j := 0; j < int(param.NamedChildCount()); j++ {
+				if name := param.NamedChild(j); name.Type() == "identifier" {
+					sig.Params = append(sig.Params, Param{Name: name.Content(source), Type: typ})
+				}
+			}
+		}
+	}
+	if result := n.ChildByFieldName("result"); result != nil {
+		sig.setReturn(result.Content(source))
+	}
+	return sig
+}
+
+func pythonSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	if n.Type() != "function_definition" {
+		return sig
+	}
+
+	if params := n.ChildByFieldName("parameters"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			param := params.NamedChild(i)
+			var p Param
+			switch param.Type() {
+			case "identifier", "list_splat_pattern", "dictionary_splat_pattern":
+				p.Name = param.Content(source)
+			case "typed_parameter":
+				p.Name = param.NamedChild(0).Content(source)
+				p.Type = fieldContent(param, "type", source)
+			case "default_parameter", "typed_default_parameter":
+				p.Name = fieldContent(param, "name", source)
+				p.Type = fieldContent(param, "type", source)
+				p.Optional = true
+			default:
+				// The bare * and / separators.
+				continue
+			}
+			if i == 0 && (p.Name == "self" || p.Name == "cls") {
+				continue
+			}
+			sig.Params = append(sig.Params, p)
+		}
+	}
+
+	body := n.ChildByFieldName("body")
+	if ret := n.ChildByFieldName("return_type"); ret != nil {
+		sig.setReturn(ret.Content(source), "None", "NoReturn")
+	} else if body != nil {
+		sig.Returns = returnsValue(body, "return_statement")
+	}
+	if body != nil {
+		sig.Throws = thrown(body, "raise_statement", source)
+	}
+	return sig
+}
+
+func jsSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	fn := n
+	switch n.Type() {
+	case "lexical_declaration", "variable_declaration":
+		if fn = declaredFunction(n, source); fn == nil {
+			return sig
+		}
+	case "function_declaration", "generator_function_declaration", "method_definition", "function_signature",
+		"arrow_function", "function_expression", "function":
+	default:
+		return sig
+	}
+
+	if params := fn.ChildByFieldName("parameters"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			if p, ok := jsParam(params.NamedChild(i), i, source); ok {
+				sig.Params = append(sig.Params, p)
+			}
+		}
+	} else if param := fn.ChildByFieldName("parameter"); param != nil {
+		// x => x * 2
+		sig.Params = append(sig.Params, Param{Name: param.Content(source)})
+	}
+
+	body := fn.ChildByFieldName("body")
+	if ret := fn.ChildByFieldName("return_type"); ret != nil {
+		returnType := strings.TrimSpace(strings.TrimPrefix(ret.Content(source), ":"))
+		sig.setReturn(returnType, "void", "never", "Promise<void>")
+	} else if body != nil && body.Type() == "statement_block" {
+		sig.Returns = returnsValue(body, "return_statement")
+	} else if body != nil {
+		// An arrow function's expression body is its return value.
+		sig.Returns = true
+	}
+	if body != nil {
+		sig.Throws = thrown(body, "throw_statement", source)
+	}
+	return sig
+}
+
+func rubySignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	switch n.Type() {
+	case "method", "singleton_method":
+	default:
+		return sig
+	}
+
+	if params := n.ChildByFieldName("parameters"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			param := params.NamedChild(i)
+			var p Param
+			switch param.Type() {
+			case "identifier":
+				p.Name = param.Content(source)
+			case "optional_parameter", "keyword_parameter":
+				p.Name = fieldContent(param, "name", source)
+				p.Optional = param.Type() == "optional_parameter" || param.ChildByFieldName("value") != nil
+			case "splat_parameter", "hash_splat_parameter", "block_parameter":
+				// A bare * or ** has no name to document.
+				p.Name = fieldContent(param, "name", source)
+				p.Optional = true
+			}
+			if p.Name != "" {
+				sig.Params = append(sig.Params, p)
+			}
+		}
+	}
+
+	name := fieldContent(n, "name", source)
+	body := n.ChildByFieldName("body")
+	switch {
+	case name == "initialize" || body == nil:
+	case strings.HasSuffix(name, "?"):
+		sig.setReturn("Boolean")
+	default:
+		// Every method returns its last expression; only say so when that
+		// looks like a value rather than a side effect.
+		sig.Returns = returnsValue(body, "return") || rubyValue(lastStatement(body), source)
+	}
+	if body != nil {
+		walkBody(body, func(stmt *sitter.Node) {
+			if stmt.Type() != "call" || fieldContent(stmt, "method", source) != "raise" || stmt.ChildByFieldName("receiver") != nil {
+				return
+			}
+			if args := stmt.ChildByFieldName("arguments"); args != nil && args.NamedChildCount() > 0 {
+				if exc := args.NamedChild(0); exc.Type() == "constant" || exc.Type() == "scope_resolution" {
+					sig.Throws = appendUnique(sig.Throws, exc.Content(source))
+				}
+			}
+		})
+	}
+	return sig
+}
+
+// rubyValue reports whether stmt, the last in a Ruby method, gives the
+// caller something: not an assignment, a raise or output.
+func rubyValue(stmt *sitter.Node, source []byte) bool {
+	if stmt == nil {
+		return false
+	}
+	switch stmt.Type() {
+	case "assignment", "operator_assignment", "if_modifier", "unless_modifier", "while_modifier", "until_modifier", "nil":
+		return false
+	case "call", "identifier":
+		if stmt.ChildByFieldName("receiver") != nil {
+			return true
+		}
+		name := stmt.Content(source)
+		if stmt.Type() == "call" {
+			name = fieldContent(stmt, "method", source)
+		}
+		switch name {
+		case "puts", "print", "p", "pp", "warn", "raise", "require":
+			return false
+		}
+	}
+	return true
+}
+
+// lastStatement returns the last statement in body that is not a comment,
+// or body itself for an expression body such as def x = 1.
+func lastStatement(body *sitter.Node) *sitter.Node {
+	if body.Type() != "body_statement" {
+		return body
+	}
+	for i := int(body.NamedChildCount()) - 1; i >= 0; i-- {
+		if stmt := body.NamedChild(i); !isCommentType(stmt.Type()) {
+			return stmt
+		}
+	}
+	return nil
+}
+
+func phpSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	switch n.Type() {
+	case "function_definition", "method_declaration":
+	default:
+		return sig
+	}
+
+	if params := n.ChildByFieldName("parameters"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			param := params.NamedChild(i)
+			switch param.Type() {
+			case "simple_parameter", "variadic_parameter", "property_promotion_parameter":
+			default:
+				continue
+			}
+			// PHPDoc names parameters with their $.
+			sig.Params = append(sig.Params, Param{
+				Name:     fieldContent(param, "name", source),
+				Type:     fieldContent(param, "type", source),
+				Optional: param.ChildByFieldName("default_value") != nil,
+			})
+		}
+	}
+
+	body := n.ChildByFieldName("body")
+	if ret := n.ChildByFieldName("return_type"); ret != nil {
+		sig.setReturn(ret.Content(source), "void", "never")
+	} else if body != nil && fieldContent(n, "name", source) != "__construct" {
+		sig.Returns = returnsValue(body, "return_statement")
+	}
+	if body != nil {
+		walkBody(body, func(stmt *sitter.Node) {
+			if stmt.Type() != "throw_expression" || stmt.NamedChildCount() == 0 {
+				return
+			}
+			if exc := stmt.NamedChild(0); exc.Type() == "object_creation_expression" && exc.NamedChildCount() > 0 {
+				sig.Throws = appendUnique(sig.Throws, exc.NamedChild(0).Content(source))
+			}
+		})
+	}
+	return sig
+}
+
+func csharpSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	var params *sitter.Node
+	switch n.Type() {
+	case "method_declaration", "constructor_declaration", "delegate_declaration", "local_function_statement":
+		params = n.ChildByFieldName("parameters")
+	case "record_declaration":
+		// The parameters of a positional record are documented as params.
+		params = findChild(n, "parameter_list")
+	default:
+		return sig
+	}
+
+	if params != nil {
+		for i := 0; i < int(params.ChildCount()); i++ {
+			param := params.Child(i)
+			switch {
+			case param.Type() == "parameter":
+				sig.Params = append(sig.Params, Param{
+					Name:     fieldContent(param, "name", source),
+					Type:     fieldContent(param, "type", source),
+					Optional: hasToken(param, "="),
+				})
+			case params.FieldNameForChild(i) == "name":
+				// A params array is not wrapped in a parameter node.
+				sig.Params = append(sig.Params, Param{Name: param.Content(source)})
+			}
+		}
+	}
+
+	for _, field := range []string{"returns", "type"} {
+		if ret := n.ChildByFieldName(field); ret != nil {
+			sig.setReturn(ret.Content(source), "void", "Task", "ValueTask")
+			break
+		}
+	}
+	if body := n.ChildByFieldName("body"); body != nil {
+		walkBody(body, func(stmt *sitter.Node) {
+			if stmt.Type() != "throw_statement" && stmt.Type() != "throw_expression" {
+				return
+			}
+			if exc := findChild(stmt, "object_creation_expression"); exc != nil {
+				sig.Throws = appendUnique(sig.Throws, fieldContent(exc, "type", source))
+			}
+		})
+	}
+	return sig
+}
+
+// hasToken reports whether token, such as the = before a default value, is
+// one of the children of n.
+func hasToken(n *sitter.Node, token string) bool {
+	for i := 0; i < int(n.ChildCount()); i++ {
+		if child := n.Child(i); !child.IsNamed() && child.Type() == token {
+			return true
+		}
+	}
+	return false
+}
+
+func swiftSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	switch n.Type() {
+	case "function_declaration", "init_declaration", "protocol_function_declaration":
+	default:
+		return sig
+	}
+
+	afterArrow := false
+	throws := false
+	for i := 0; i < int(n.ChildCount()); i++ {
+		child := n.Child(i)
+		switch {
+		case child.Type() == "parameter":
+			// The parameter's own name, not its argument label, is the one
+			// documentation refers to.
+			p := Param{Name: fieldContent(child, "name", source)}
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				if part := child.NamedChild(j); strings.HasSuffix(part.Type(), "_type") {
+					p.Type = part.Content(source)
+				}
+			}
+			sig.Params = append(sig.Params, p)
+		case child.Type() == "default_value" && len(sig.Params) > 0:
+			sig.Params[len(sig.Params)-1].Optional = true
+		case child.Type() == "throws":
+			throws = true
+		case child.Type() == "->":
+			afterArrow = true
+		case afterArrow && child.IsNamed():
+			sig.setReturn(child.Content(source), "Void", "()", "Never")
+			afterArrow = false
+		}
+	}
+
+	if body := n.ChildByFieldName("body"); body != nil {
+		walkBody(body, func(stmt *sitter.Node) {
+			if stmt.Type() != "control_transfer_statement" || findChild(stmt, "throw_keyword") == nil {
+				return
+			}
+			if call := findChild(stmt, "call_expression"); call != nil && call.NamedChildCount() > 0 {
+				sig.Throws = appendUnique(sig.Throws, call.NamedChild(0).Content(source))
+			}
+		})
+	}
+	if throws && len(sig.Throws) == 0 {
+		sig.Throws = []string{"Error"}
+	}
+	return sig
+}
+
+// sqlSignature reads the columns of a SQL table or the parameters of a
+// function. Output parameters are left out, since they are results.
+func sqlSignature(n *sitter.Node, source []byte) Signature {
+	var sig Signature
+	switch n.Type() {
+	case "create_table":
+		if defs := findChild(n, "column_definitions"); defs != nil {
+			for i := 0; i < int(defs.NamedChildCount()); i++ {
+				if col := defs.NamedChild(i); col.Type() == "column_definition" {
+					sig.Columns = append(sig.Columns, Param{
+						Name: fieldContent(col, "name", source),
+						Type: fieldContent(col, "type", source),
+					})
+				}
+			}
+		}
+	case "create_function":
+		sig.setSQLReturn(sqlReturnType(n, source))
+		args := findChild(n, "function_arguments")
+		if args == nil {
+			return sig
+		}
+		for i := 0; i < int(args.NamedChildCount()); i++ {
+			arg := args.NamedChild(i)
+			if arg.Type() != "function_argument" {
+				continue
+			}
+			var p Param
+			out := false
+			for j := 0; j < int(arg.NamedChildCount()); j++ {
+				part := arg.NamedChild(j)
+				switch part.Type() {
+				case "keyword_in", "keyword_inout", "keyword_variadic":
+				case "keyword_out":
+					out = true
+				case "keyword_default":
+					p.Optional = true
+				case "identifier":
+					if p.Name == "" && p.Type == "" {
+						p.Name = part.Content(source)
+						continue
+					}
+					fallthrough
+				default:
+					if p.Type == "" && !p.Optional {
+						p.Type = part.Content(source)
+					}
+				}
+			}
+			if !out {
+				sig.Params = append(sig.Params, p)
+			}
+		}
+	}
+	return sig
+}
+
+// sqlReturnType returns the type in a create_function's RETURNS clause: the
+// nodes between RETURNS and the body or the first routine characteristic.
+func sqlReturnType(n *sitter.Node, source []byte) string {
+	var from, to uint32
+clause:
+	for i := 0; i < int(n.ChildCount()); i++ {
+		child := n.Child(i)
+		switch {
+		case child.Type() == "keyword_returns":
+			from = child.EndByte()
+		case from == 0:
+		case strings.HasPrefix(child.Type(), "function_") || child.Type() == "keyword_as":
+			break clause
+		default:
+			to = child.EndByte()
+		}
+	}
+	if to < from {
+		return ""
+	}
+	return string(source[from:to])
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// jsParam reads parameter i of a JavaScript or TypeScript function.
+// Destructured parameters have no name of their own and are called paramN,
+// as JSDoc does.
+func jsParam(param *sitter.Node, i int, source []byte) (Param, bool) {
+	var p Param
+	pattern := param
+	switch param.Type() {
+	case "required_parameter", "optional_parameter":
+		pattern = param.ChildByFieldName("pattern")
+		if annotation := param.ChildByFieldName("type"); annotation != nil {
+			p.Type = strings.TrimSpace(strings.TrimPrefix(annotation.Content(source), ":"))
+		}
+		p.Optional = param.Type() == "optional_parameter" || param.ChildByFieldName("value") != nil
+	case "assignment_pattern":
+		pattern = param.ChildByFieldName("left")
+		p.Optional = true
+	case "comment":
+		return p, false
+	}
+	if pattern == nil {
+		return p, false
+	}
+
+	switch pattern.Type() {
+	case "identifier":
+		p.Name = pattern.Content(source)
+	case "rest_pattern":
+		p.Name = strings.TrimPrefix(pattern.Content(source), "...")
+	case "this":
+		return p, false
+	default:
+		p.Name = fmt.Sprintf("param%d", i)
+	}
+	return p, true
+}
+
+// returnsValue reports whether body, not counting the functions nested in
+// it, has a statement of type returnType that gives back a value.
+func returnsValue(body *sitter.Node, returnType string) bool {
+	found := false
+	walkBody(body, func(n *sitter.Node) {
+		if n.Type() == returnType && n.NamedChildCount() > 0 && !isCommentType(n.NamedChild(0).Type()) {
+			found = true
+		}
+	})
+	return found
+}
+
+// thrown returns the exception classes raised by statements of type
+// statementType in body, in order of first appearance. Re-raised variables
+// are left out; only names that look like classes are kept.
+func thrown(body *sitter.Node, statementType string, source []byte) []string {
+	var names []string
+	seen := map[string]bool{}
+	walkBody(body, func(n *sitter.Node) {
+		if n.Type() != statementType || n.NamedChildCount() == 0 {
+			return
+		}
+		exc := n.NamedChild(0)
+		switch exc.Type() {
+		case "call":
+			exc = exc.ChildByFieldName("function")
+		case "new_expression":
+			exc = exc.ChildByFieldName("constructor")
+		}
+		if exc == nil {
+			return
+		}
+		name := exc.Content(source)
+		last := []rune(name[strings.LastIndex(name, ".")+1:])
+		if len(last) == 0 || !unicode.IsUpper(last[0]) || seen[name] {
+			return
+		}
+		seen[name] = true
+		names = append(names, name)
+	})
+	return names
+}
+
+// walkBody calls visit for every node in body except those inside nested
+// functions and classes.
+func walkBody(n *sitter.Node, visit func(*sitter.Node)) {
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		switch child.Type() {
+		case "function_definition", "class_definition", "lambda",
+			"function_declaration", "generator_function_declaration", "function_expression", "function",
+			"arrow_function", "method_definition", "class_declaration", "class",
+			"method", "singleton_method", "module", "anonymous_function", "anonymous_function_creation_expression",
+			"local_function_statement", "lambda_expression", "lambda_literal":
+			continue
+		}
+		visit(child)
+		walkBody(child, visit)
+	}
+}
+
+func fieldContent(n *sitter.Node, field string, source []byte) string {
+	if child := n.ChildByFieldName(field); child != nil {
+		return child.Content(source)
+	}
+	return ""
+}
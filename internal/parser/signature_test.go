@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// signatureOf parses source as filename and returns the signature of the
+// block called name.
+func signatureOf(t *testing.T, filename, source, name string) Signature {
+	t.Helper()
+	p, err := NewParser(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := p.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range blocks {
+		if b.Name == name {
+			return b.Signature
+		}
+	}
+	t.Fatalf("no block %s in %s", name, filename)
+	return Signature{}
+}
+
+func TestCSignature(t *testing.T) {
+	source := `int copy(char *dst, const char *src, size_t n, int grid[4][8], char **argv, char *const tag, int (*cmp)(const void *, const void *), void *) {
+	return 0;
+}
+`
+	got := signatureOf(t, "copy.c", source, "copy")
+	want := []Param{
+		{Name: "dst", Type: "char *"},
+		{Name: "src", Type: "const char *"},
+		{Name: "n", Type: "size_t"},
+		{Name: "grid", Type: "int[4][8]"},
+		{Name: "argv", Type: "char **"},
+		{Name: "tag", Type: "char *const"},
+		{Name: "cmp", Type: "int (*)(const void *, const void *)"},
+	}
+	if !reflect.DeepEqual(got.Params, want) {
+		t.Errorf("Params = %+v, want %+v", got.Params, want)
+	}
+	if !got.Returns || got.ReturnType != "int" {
+		t.Errorf("Returns = %v %q, want int", got.Returns, got.ReturnType)
+	}
+}
+
+func TestCppSignature(t *testing.T) {
+	source := `std::string *join(const std::vector<std::string> &parts, std::string &&sep, int limit = 10) {
+	return nullptr;
+}
+`
+	got := signatureOf(t, "join.cpp", source, "join")
+	want := []Param{
+		{Name: "parts", Type: "const std::vector<std::string> &"},
+		{Name: "sep", Type: "std::string &&"},
+		{Name: "limit", Type: "int"},
+	}
+	if !reflect.DeepEqual(got.Params, want) {
+		t.Errorf("Params = %+v, want %+v", got.Params, want)
+	}
+	if got.ReturnType != "std::string *" {
+		t.Errorf("ReturnType = %q, want std::string *", got.ReturnType)
+	}
+}
+
+func TestJavaSignature(t *testing.T) {
+	source := `class Main {
+    public static int run(final String name, String... args) throws IOException {
+        return 0;
+    }
+}
+`
+	got := signatureOf(t, "Main.java", source, "run")
+	want := []Param{
+		{Name: "name", Type: "String"},
+		{Name: "args", Type: "String..."},
+	}
+	if !reflect.DeepEqual(got.Params, want) {
+		t.Errorf("Params = %+v, want %+v", got.Params, want)
+	}
+	if !reflect.DeepEqual(got.Throws, []string{"IOException"}) {
+		t.Errorf("Throws = %v, want [IOException]", got.Throws)
+	}
+}
+
+func TestSQLSignatureReturns(t *testing.T) {
+	tests := []struct {
+		name, source, block string
+		returns             string
+	}{
+		{"scalar", "CREATE FUNCTION add_one(x integer) RETURNS integer AS $$ SELECT x + 1 $$ LANGUAGE sql;", "add_one", "integer"},
+		{"table", "CREATE FUNCTION pairs(a int) RETURNS TABLE (id int, name text) AS $$ SELECT 1, 'a' $$ LANGUAGE sql;", "pairs", "TABLE (id int, name text)"},
+		{"setof", "CREATE FUNCTION everyone() RETURNS SETOF users AS $$ SELECT * FROM users $$ LANGUAGE sql;", "everyone", "SETOF users"},
+		{"void", "CREATE FUNCTION touch() RETURNS void AS $$ BEGIN END $$ LANGUAGE plpgsql;", "touch", ""},
+		{"mysql", "CREATE FUNCTION total(cutoff DATE) RETURNS DECIMAL(10,2)\nDETERMINISTIC\nBEGIN\n  RETURN 0;\nEND;", "total", "DECIMAL(10,2)"},
+		{"procedure", "CREATE PROCEDURE archive(IN cutoff DATE)\nBEGIN\n  SELECT 1;\nEND;", "archive", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := signatureOf(t, "routines.sql", tt.source, tt.block)
+			if got.Returns != (tt.returns != "") || got.ReturnType != tt.returns {
+				t.Errorf("Returns = %v %q, want %q", got.Returns, got.ReturnType, tt.returns)
+			}
+		})
+	}
+}
+
+func TestOptionalParams(t *testing.T) {
+	tests := []struct {
+		name, filename, source, block string
+		want                          []Param
+	}{
+		{
+			name:     "kotlin",
+			filename: "find.kt",
+			source:   "fun find(id: Int, limit: Int = 10, sort: String = \"id\", vararg tags: String) {}\n",
+			block:    "find",
+			want: []Param{
+				{Name: "id", Type: "Int"},
+				{Name: "limit", Type: "Int", Optional: true},
+				{Name: "sort", Type: "String", Optional: true},
+				{Name: "tags", Type: "String"},
+			},
+		},
+		{
+			name:     "kotlin constructor",
+			filename: "repo.kt",
+			source:   "class Repo {\n    constructor(db: String, retries: Int = 3) {}\n}\n",
+			block:    "constructor",
+			want: []Param{
+				{Name: "db", Type: "String"},
+				{Name: "retries", Type: "Int", Optional: true},
+			},
+		},
+		{
+			name:     "csharp",
+			filename: "Find.cs",
+			source:   "class C {\n    void Find(int id, int limit = 10, string sort = \"id\") {}\n}\n",
+			block:    "Find",
+			want: []Param{
+				{Name: "id", Type: "int"},
+				{Name: "limit", Type: "int", Optional: true},
+				{Name: "sort", Type: "string", Optional: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := signatureOf(t, tt.filename, tt.source, tt.block)
+			if !reflect.DeepEqual(got.Params, tt.want) {
+				t.Errorf("Params = %+v, want %+v", got.Params, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,495 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// SQLDialects are the dialects SQL can be declared or detected as.
+var SQLDialects = []string{"postgres", "mysql"}
+
+// ValidateSQLDialect checks a configured SQL dialect; "" means detect it.
+func ValidateSQLDialect(dialect string) error {
+	if dialect == "" {
+		return nil
+	}
+	for _, d := range SQLDialects {
+		if dialect == d {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown SQL dialect %q (want postgres or mysql)", dialect)
+}
+
+// dialectSyntax matches syntax only one dialect has.
+var dialectSyntax = map[string]*regexp.Regexp{
+	"postgres": regexp.MustCompile(`(?i)\$\$|::[a-z]|\b(big)?serial\b|\bplpgsql\b|\bjsonb\b|\btimestamptz\b|\bilike\b|\breturning\b|\bcreate\s+extension\b`),
+	"mysql":    regexp.MustCompile("(?im)`|\\bauto_increment\\b|\\bengine\\s*=|^[ \\t]*delimiter\\b|\\bunsigned\\b|\\btinyint\\b|\\bon\\s+duplicate\\s+key\\b"),
+}
+
+// SQLDialect guesses whether SQL source is written for PostgreSQL or MySQL
+// from the syntax only one of them has, such as $$ bodies or backticks. It
+// returns "" when neither stands out.
+func SQLDialect(source []byte) string {
+	best, bestCount, tie := "", 0, false
+	for _, dialect := range SQLDialects {
+		count := len(dialectSyntax[dialect].FindAllIndex(source, -1))
+		switch {
+		case count > bestCount:
+			best, bestCount, tie = dialect, count, false
+		case count == bestCount:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return best
+}
+
+// templateTag matches the Jinja tags in dbt models and other templated SQL.
+var templateTag = regexp.MustCompile(`(?s)\{\{.*?\}\}|\{%.*?%\}|\{#.*?#\}`)
+
+// maskTemplates blanks out template tags so the SQL around them parses. An
+// expression within a line, such as {{ ref('orders') }}, becomes an
+// identifier of the same length; other tags become spaces. Offsets and
+// lines stay the same.
+func maskTemplates(source []byte) []byte {
+	tags := templateTag.FindAllIndex(source, -1)
+	if tags == nil {
+		return source
+	}
+	masked := append([]byte(nil), source...)
+	for _, tag := range tags {
+		fill := byte(' ')
+		if source[tag[0]+1] == '{' && !onOwnLines(source, uint32(tag[0]), uint32(tag[1])) {
+			fill = '_'
+		}
+		for i := tag[0]; i < tag[1]; i++ {
+			if masked[i] != '\n' {
+				masked[i] = fill
+			}
+		}
+	}
+	return masked
+}
+
+// sqlName matches a possibly qualified and quoted object name.
+const sqlIdent = "(?:[A-Za-z_][\\w$]*|`[^`]+`|\"[^\"]+\"|\\[[^\\]]+\\])"
+const sqlName = sqlIdent + `(?:\.` + sqlIdent + `)*`
+
+var (
+	routineStart     = regexp.MustCompile(`(?im)^[ \t]*(CREATE\s+(?:OR\s+REPLACE\s+)?(?:DEFINER\s*=\s*\S+\s+)?(PROCEDURE|FUNCTION)\s+(` + sqlName + `))`)
+	commentOnStart   = regexp.MustCompile(`(?im)^[ \t]*(COMMENT\s+ON\s+(TABLE|COLUMN|VIEW|MATERIALIZED\s+VIEW|FUNCTION|PROCEDURE)\s+(` + sqlName + `))`)
+	delimiterCommand = regexp.MustCompile(`(?im)^[ \t]*DELIMITER[ \t]+(\S+)`)
+	sqlWord          = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+	dollarTag        = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+	// returnsClause matches a function's RETURNS clause up to the first
+	// routine characteristic or body.
+	returnsClause = regexp.MustCompile(`(?is)^\s*RETURNS\s+(.+?)\s*(?:\b(?:AS|LANGUAGE|BEGIN|RETURN|DETERMINISTIC|NOT|NO|READS|MODIFIES|CONTAINS|SQL|IMMUTABLE|STABLE|VOLATILE|STRICT|CALLED|SECURITY|PARALLEL|COST|ROWS|SET|COMMENT|WINDOW|LEAKPROOF)\b|\$|;|$)`)
+)
+
+// sqlBlocks completes the blocks the query found in a SQL file: statements
+// take their terminating semicolon, routines the grammar could not parse are
+// recovered from the text, and blocks documented with COMMENT ON statements
+// rather than comments get those as their doc.
+func sqlBlocks(root *sitter.Node, source []byte, comments commentIndex, blocks []CodeBlock) []CodeBlock {
+	for i := range blocks {
+		withSemicolon(&blocks[i], source)
+	}
+	blocks = append(blocks, recoverRoutines(root, source, comments, blocks)...)
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].StartByte < blocks[j].StartByte
+	})
+
+	statements := commentOnStatements(source)
+	for i := range blocks {
+		if blocks[i].DocComment != "" {
+			continue
+		}
+		doc := commentOnDoc(blocks[i], statements, comments, source)
+		blocks[i].DocComment = doc.text
+		blocks[i].DocStartLine = doc.startRow
+		blocks[i].DocEndLine = doc.endRow
+	}
+	return blocks
+}
+
+// withSemicolon extends block over the semicolon ending its statement, which
+// the grammar leaves outside the statement.
+func withSemicolon(block *CodeBlock, source []byte) {
+	end := int(block.EndByte)
+	for end < len(source) && strings.ContainsRune(" \t\r\n", rune(source[end])) {
+		end++
+	}
+	if end < len(source) && source[end] == ';' {
+		block.EndByte = uint32(end + 1)
+		block.Code = string(source[block.StartByte:block.EndByte])
+		block.EndLine = block.StartLine + uint32(strings.Count(block.Code, "\n"))
+	}
+}
+
+// recoverRoutines finds the CREATE PROCEDURE and CREATE FUNCTION statements
+// the grammar failed on, such as MySQL routines with BEGIN ... END bodies or
+// any procedure at all, in the parse errors of root.
+func recoverRoutines(root *sitter.Node, source []byte, comments commentIndex, blocks []CodeBlock) []CodeBlock {
+	found := map[uint32]bool{}
+	for _, block := range blocks {
+		found[block.StartByte] = true
+	}
+	var failed []*sitter.Node
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if n.IsError() {
+			failed = append(failed, n)
+			return
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			walk(n.Child(i))
+		}
+	}
+	walk(root)
+
+	var routines []CodeBlock
+	for _, m := range routineStart.FindAllSubmatchIndex(source, -1) {
+		start := uint32(m[2])
+		if found[start] || !inNodes(failed, start) {
+			continue
+		}
+		end := statementEnd(source, m[2], delimiterAt(source, m[2]))
+		row := uint32(bytes.Count(source[:start], []byte("\n")))
+		code := string(source[start:end])
+		doc := comments.docAbove(row, source, "sql")
+		routines = append(routines, CodeBlock{
+			Type:         strings.ToLower(string(source[m[4]:m[5]])),
+			Name:         string(source[m[6]:m[7]]),
+			StartLine:    row,
+			EndLine:      row + uint32(strings.Count(code, "\n")),
+			StartByte:    start,
+			EndByte:      uint32(end),
+			Code:         code,
+			AnchorLine:   row,
+			DocComment:   doc.text,
+			DocStartLine: doc.startRow,
+			DocEndLine:   doc.endRow,
+			Exported:     true,
+			Signature:    routineSignature(source[m[7]:end]),
+			callable:     true,
+		})
+	}
+	return routines
+}
+
+func inNodes(nodes []*sitter.Node, at uint32) bool {
+	for _, n := range nodes {
+		if at >= n.StartByte() && at < n.EndByte() {
+			return true
+		}
+	}
+	return false
+}
+
+// delimiterAt returns the statement delimiter in effect at byte at, as set
+// by the last MySQL DELIMITER command before it.
+func delimiterAt(source []byte, at int) string {
+	delimiter := ";"
+	for _, m := range delimiterCommand.FindAllSubmatchIndex(source[:at], -1) {
+		delimiter = string(source[m[2]:m[3]])
+	}
+	return delimiter
+}
+
+// statementEnd returns the byte after the delimiter ending the statement
+// that starts at from, skipping strings, comments and dollar-quoted bodies.
+// With the ; delimiter, semicolons between BEGIN and END do not count.
+func statementEnd(source []byte, from int, delimiter string) int {
+	depth := 0
+	for i := from; i < len(source); {
+		rest := source[i:]
+		switch c := source[i]; {
+		case bytes.HasPrefix(rest, []byte("--")):
+			i = skipPast(source, i, "\n")
+		case bytes.HasPrefix(rest, []byte("/*")):
+			i = skipPast(source, i+2, "*/")
+		case c == '\'' || c == '"' || c == '`':
+			i = skipPast(source, i+1, string(c))
+		case dollarTag.Match(rest):
+			tag := dollarTag.Find(rest)
+			i = skipPast(source, i+len(tag), string(tag))
+		case bytes.HasPrefix(rest, []byte(delimiter)) && (delimiter != ";" || depth == 0):
+			return i + len(delimiter)
+		case sqlWord.Match(rest) && (i == 0 || !isWordByte(source[i-1])):
+			word := sqlWord.Find(rest)
+			i += len(word)
+			switch strings.ToUpper(string(word)) {
+			case "BEGIN", "CASE":
+				depth++
+			case "END":
+				// END IF and END LOOP close blocks that were not counted.
+				next := sqlWord.Find(bytes.TrimLeft(source[i:], " \t\r\n"))
+				switch strings.ToUpper(string(next)) {
+				case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+				default:
+					if depth > 0 {
+						depth--
+					}
+				}
+			}
+		default:
+			i++
+		}
+	}
+	return len(source)
+}
+
+// skipPast returns the byte after the first closing after from, or the end
+// of source.
+func skipPast(source []byte, from int, closing string) int {
+	if i := bytes.Index(source[from:], []byte(closing)); i >= 0 {
+		return from + i + len(closing)
+	}
+	return len(source)
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// routineSignature parses the parameter list and RETURNS clause of a
+// routine from rest, the text after its name.
+func routineSignature(rest []byte) Signature {
+	params, after := routineParams(rest)
+	sig := Signature{Params: params}
+	if m := returnsClause.FindStringSubmatch(after); m != nil {
+		sig.setSQLReturn(m[1])
+	}
+	return sig
+}
+
+// setSQLReturn records the type a SQL function is declared to return.
+func (s *Signature) setSQLReturn(returnType string) {
+	returnType = strings.Join(strings.Fields(returnType), " ")
+	if returnType != "" && !strings.EqualFold(returnType, "void") {
+		s.setReturn(returnType)
+	}
+}
+
+// routineParams parses the parameter list opening rest, as in
+// (IN cutoff DATE, OUT total INT), and returns it with the text after it.
+// Output parameters are left out, since they are results.
+func routineParams(rest []byte) ([]Param, string) {
+	text := strings.TrimLeft(string(rest), " \t\r\n")
+	if !strings.HasPrefix(text, "(") {
+		return nil, text
+	}
+	var parts []string
+	depth, start := 0, 1
+	after := ""
+list:
+	for i, r := range text {
+		switch r {
+		case '(':
+			depth++
+		case ',':
+			if depth == 1 {
+				parts = append(parts, text[start:i])
+				start = i + 1
+			}
+		case ')':
+			if depth--; depth == 0 {
+				parts = append(parts, text[start:i])
+				after = text[i+1:]
+				break list
+			}
+		}
+	}
+
+	var params []Param
+	for _, part := range parts {
+		fields := strings.Fields(part)
+		if len(fields) > 0 {
+			switch strings.ToUpper(fields[0]) {
+			case "OUT":
+				continue
+			case "IN", "INOUT", "VARIADIC":
+				fields = fields[1:]
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		param := Param{Name: fields[0], Type: strings.Join(fields[1:], " ")}
+		if len(fields) == 1 {
+			// PostgreSQL parameters may be a bare type.
+			param = Param{Type: fields[0]}
+		}
+		for _, sep := range []string{" DEFAULT ", " default ", "=", " := "} {
+			if before, _, ok := strings.Cut(param.Type, sep); ok {
+				param.Type = strings.TrimSpace(before)
+				param.Optional = true
+			}
+		}
+		params = append(params, param)
+	}
+	return params, after
+}
+
+// commentOn is a COMMENT ON statement, which documents a table, view,
+// column or routine in the database itself.
+type commentOn struct {
+	// kind is what is commented on, such as TABLE or MATERIALIZED VIEW,
+	// and target its name, table.column for a column.
+	kind      string
+	target    string
+	startByte uint32
+	endByte   uint32
+	startRow  uint32
+	endRow    uint32
+}
+
+// commentOnStatements finds the COMMENT ON statements in source. They are
+// matched in the text because the grammar does not know them all.
+func commentOnStatements(source []byte) []commentOn {
+	var statements []commentOn
+	for _, m := range commentOnStart.FindAllSubmatchIndex(source, -1) {
+		end := statementEnd(source, m[2], delimiterAt(source, m[2]))
+		startRow := uint32(bytes.Count(source[:m[2]], []byte("\n")))
+		statements = append(statements, commentOn{
+			kind:      strings.Join(strings.Fields(strings.ToUpper(string(source[m[4]:m[5]]))), " "),
+			target:    string(source[m[6]:m[7]]),
+			startByte: uint32(m[2]),
+			endByte:   uint32(end),
+			startRow:  startRow,
+			endRow:    startRow + uint32(bytes.Count(source[m[2]:end], []byte("\n"))),
+		})
+	}
+	return statements
+}
+
+// commentOnKinds maps block types to the object kinds COMMENT ON names.
+var commentOnKinds = map[string]string{
+	"table":             "TABLE",
+	"view":              "VIEW",
+	"materialized_view": "MATERIALIZED VIEW",
+	"function":          "FUNCTION",
+	"procedure":         "PROCEDURE",
+}
+
+// CommentOnKind returns the kind of object a COMMENT ON statement names for
+// a SQL block of blockType, or "" if there is none, as for queries.
+func CommentOnKind(blockType string) string {
+	return commentOnKinds[blockType]
+}
+
+var sqlIdentPattern = regexp.MustCompile(sqlIdent)
+
+// sqlNameParts splits a possibly qualified name into its parts. Unquoted
+// parts are folded to lower case, as the database folds them, and quoted
+// parts keep their case without the quotes.
+func sqlNameParts(name string) []string {
+	parts := sqlIdentPattern.FindAllString(name, -1)
+	for i, part := range parts {
+		switch part[0] {
+		case '`', '"', '[':
+			parts[i] = part[1 : len(part)-1]
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return parts
+}
+
+// sameSQLName reports whether two split names refer to the same object. When
+// one is less qualified, as users is than public.users, only the parts it
+// has are compared.
+func sameSQLName(a, b []string) bool {
+	n := min(len(a), len(b))
+	return n > 0 && slices.Equal(a[len(a)-n:], b[len(b)-n:])
+}
+
+// documents reports whether s comments on block or one of its columns.
+func (s commentOn) documents(block CodeBlock) bool {
+	name := sqlNameParts(block.Name)
+	target := sqlNameParts(s.target)
+	if s.kind == "COLUMN" {
+		return block.Type == "table" && len(target) > 1 && sameSQLName(target[:len(target)-1], name)
+	}
+	return s.kind == commentOnKinds[block.Type] && sameSQLName(target, name)
+}
+
+// commentOnDoc returns the COMMENT ON statements documenting block. Those
+// right below it, with any comments among them such as a provenance marker,
+// are its doc; a statement elsewhere in the file still shows the block is
+// documented, but is not something annotr would replace.
+func commentOnDoc(block CodeBlock, statements []commentOn, comments commentIndex, source []byte) docSpan {
+	var doc docSpan
+	row := block.EndLine + 1
+	pending := row
+	for {
+		if c, ok := comments[row]; ok && c.startRow == row {
+			row++
+			continue
+		}
+		i := sort.Search(len(statements), func(i int) bool {
+			return statements[i].startRow >= row
+		})
+		if i == len(statements) || statements[i].startRow != row || !statements[i].documents(block) {
+			break
+		}
+		if doc.text == "" {
+			doc.startRow = pending
+		}
+		doc.endRow = statements[i].endRow
+		doc.text = string(source[lineStart(source, doc.startRow):statements[i].endByte])
+		row = statements[i].endRow + 1
+	}
+	if doc.text != "" {
+		return doc
+	}
+
+	for _, s := range statements {
+		if s.documents(block) {
+			return docSpan{
+				text:     string(source[s.startByte:s.endByte]),
+				startRow: s.startRow,
+				endRow:   s.endRow,
+			}
+		}
+	}
+	return doc
+}
+
+// lineStart returns the offset of the first byte of line row.
+func lineStart(source []byte, row uint32) int {
+	start := 0
+	for ; row > 0; row-- {
+		i := bytes.IndexByte(source[start:], '\n')
+		if i < 0 {
+			return len(source)
+		}
+		start += i + 1
+	}
+	return start
+}
+
+// commentOnComments returns the COMMENT ON statements in source as
+// comments, which is what they are to the database.
+func commentOnComments(source []byte) []Comment {
+	var comments []Comment
+	for _, s := range commentOnStatements(source) {
+		comments = append(comments, Comment{
+			StartLine: s.startRow,
+			EndLine:   s.endRow,
+			StartByte: s.startByte,
+			EndByte:   s.endByte,
+			Text:      string(source[s.startByte:s.endByte]),
+		})
+	}
+	return comments
+}
@@ -0,0 +1,16 @@
+namespace Sample
+{
+    public interface IStore
+    {
+        string Get(string key);
+    }
+
+    public class Store : IStore
+    {
+        public string Name { get; set; }
+
+        public string Get(string key) => key;
+    }
+
+    public record Item(string Id);
+}
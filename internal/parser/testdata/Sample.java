@@ -0,0 +1,20 @@
+package sample;
+
+/** A sample. */
+public class Sample {
+    private int count;
+
+    public Sample(int count) {
+        this.count = count;
+    }
+
+    public int next() throws IllegalStateException {
+        return ++count;
+    }
+
+    interface Listener {
+        void on(String event);
+    }
+
+    enum Mode { ON, OFF }
+}
@@ -0,0 +1,13 @@
+#include <stdio.h>
+
+struct point {
+    int x;
+};
+
+static int add(int a, int b) {
+    return a + b;
+}
+
+int main(void) {
+    return add(1, 2);
+}
@@ -0,0 +1,10 @@
+package sample
+
+// Greeter says hello.
+type Greeter struct{}
+
+func (g *Greeter) Greet(name string) string {
+	return "hello " + name
+}
+
+func helper() {}
@@ -0,0 +1,17 @@
+#pragma once
+
+namespace geo {
+
+class Shape {
+public:
+    virtual double area() const = 0;
+};
+
+double scale(double value, double factor);
+
+template <typename T>
+T clamp(T v, T lo, T hi) {
+    return v < lo ? lo : v;
+}
+
+}
@@ -0,0 +1,16 @@
+/** Adds two numbers. */
+export function add(a, b = 0) {
+  return a + b;
+}
+
+const double = (x) => x * 2;
+
+class Counter {
+  increment() {}
+}
+
+const api = {
+  fetch: function () {},
+};
+
+[1, 2].map((x) => x + 1);
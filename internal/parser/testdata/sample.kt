@@ -0,0 +1,15 @@
+package sample
+
+class Repo(private val db: String) {
+    fun find(id: Int): String? = null
+}
+
+object Registry
+
+fun topLevel(name: String): Unit {}
+
+interface Source {
+    fun load(): String
+}
+
+enum class Level { LOW, HIGH }
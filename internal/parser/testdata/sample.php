@@ -0,0 +1,15 @@
+<?php
+
+namespace App;
+
+interface Payable {
+    public function pay(float $amount): bool;
+}
+
+class Order implements Payable {
+    public function pay(float $amount): bool {
+        return true;
+    }
+}
+
+function helper(string $name = "x") {}
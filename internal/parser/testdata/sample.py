@@ -0,0 +1,18 @@
+import os
+
+
+class Store:
+    """Keeps things."""
+
+    def get(self, key, default=None):
+        return default
+
+    @staticmethod
+    def _reset():
+        pass
+
+
+def main():
+    def inner():
+        pass
+    return inner
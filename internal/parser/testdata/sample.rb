@@ -0,0 +1,11 @@
+module Billing
+  class Invoice
+    def total(items)
+      items.sum
+    end
+
+    private
+
+    def secret; end
+  end
+end
@@ -0,0 +1,24 @@
+//! Sample crate.
+
+/// A point.
+pub struct Point {
+    x: i32,
+}
+
+pub enum Shape {
+    Circle,
+}
+
+pub trait Area {
+    fn area(&self) -> f64;
+}
+
+impl Point {
+    pub fn new(x: i32) -> Self {
+        Point { x }
+    }
+}
+
+mod inner {
+    fn hidden() {}
+}
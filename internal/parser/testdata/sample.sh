@@ -0,0 +1,12 @@
+#!/usr/bin/env bash
+# Deploys the app.
+set -euo pipefail
+
+build() {
+  make build
+}
+
+function deploy {
+  build
+  echo "$TARGET"
+}
@@ -0,0 +1,15 @@
+CREATE TABLE users (
+    id integer PRIMARY KEY,
+    email text NOT NULL
+);
+
+CREATE VIEW active_users AS SELECT * FROM users;
+
+CREATE FUNCTION user_count() RETURNS integer AS $$ SELECT count(*) FROM users $$ LANGUAGE sql;
+
+WITH recent AS (
+    SELECT * FROM users
+)
+SELECT * FROM recent;
+
+SELECT 1;
@@ -0,0 +1,15 @@
+import Foundation
+
+public protocol Greeter {
+    func greet(name: String) -> String
+}
+
+public struct Hello: Greeter {
+    public func greet(name: String) -> String {
+        return "hi \(name)"
+    }
+}
+
+enum Mood {
+    case happy
+}
@@ -0,0 +1,15 @@
+export interface Shape {
+  area(): number;
+}
+
+export enum Color {
+  Red,
+}
+
+export abstract class Base {
+  abstract name(): string;
+}
+
+export function area(s: Shape): number {
+  return s.area();
+}
@@ -0,0 +1,12 @@
+import { memo, useState } from "react";
+
+export function Button({ label }: { label: string }) {
+  return <button>{label}</button>;
+}
+
+export const Card = memo(() => <div />);
+
+export function useToggle(initial: boolean) {
+  const [on, setOn] = useState(initial);
+  return [on, () => setOn(!on)] as const;
+}
@@ -0,0 +1,297 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	ReviewPending  = "pending"
+	ReviewAccepted = "accepted"
+	ReviewRejected = "rejected"
+)
+
+// maxReviewCodeLines caps how much of a block is shown under its comment.
+const maxReviewCodeLines = 20
+
+type ReviewItem struct {
+	Name string
+	// Line is the 1-based line the comment will be inserted at.
+	Line    int
+	Code    string
+	Comment string
+	Status  string
+}
+
+// RegenerateFunc produces a fresh comment for the item at index.
+type RegenerateFunc func(index int) (string, error)
+
+type ReviewModel struct {
+	file       string
+	items      []ReviewItem
+	cursor     int
+	editing    bool
+	editor     textarea.Model
+	regenerate RegenerateFunc
+	busy       bool
+	err        error
+	finished   bool
+	quitting   bool
+}
+
+func NewReviewModel(file string, items []ReviewItem, regenerate RegenerateFunc) ReviewModel {
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.SetHeight(8)
+	ta.SetWidth(76)
+
+	m := ReviewModel{
+		file:       file,
+		items:      items,
+		editor:     ta,
+		regenerate: regenerate,
+	}
+	// Resume at the first item still waiting for a decision.
+	m.cursor = m.nextPending(-1)
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	return m
+}
+
+type regenerateMsg struct {
+	index   int
+	comment string
+	err     error
+}
+
+func (m ReviewModel) regenerateCmd(index int) tea.Cmd {
+	return func() tea.Msg {
+		comment, err := m.regenerate(index)
+		return regenerateMsg{index: index, comment: comment, err: err}
+	}
+}
+
+func (m ReviewModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if msg.Width > 20 {
+			m.editor.SetWidth(msg.Width - 4)
+		}
+		return m, nil
+
+	case regenerateMsg:
+		m.busy = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.items[msg.index].Comment = msg.comment
+			m.items[msg.index].Status = ReviewPending
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.editing {
+			return m.updateEditor(msg)
+		}
+		return m.handleKey(msg)
+	}
+
+	if m.editing {
+		var cmd tea.Cmd
+		m.editor, cmd = m.editor.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m ReviewModel) updateEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.editing = false
+		m.editor.Blur()
+		return m, nil
+	case "ctrl+s":
+		m.items[m.cursor].Comment = strings.TrimRight(m.editor.Value(), "\n")
+		m.items[m.cursor].Status = ReviewAccepted
+		m.editing = false
+		m.editor.Blur()
+		m.advance()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return m, cmd
+}
+
+func (m ReviewModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 {
+		m.finished = true
+		return m, tea.Quit
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "w":
+		m.finished = true
+		return m, tea.Quit
+	case "a", "y":
+		m.items[m.cursor].Status = ReviewAccepted
+		m.advance()
+	case "r", "n":
+		m.items[m.cursor].Status = ReviewRejected
+		m.advance()
+	case "A":
+		for i := range m.items {
+			if m.items[i].Status == ReviewPending {
+				m.items[i].Status = ReviewAccepted
+			}
+		}
+	case "e":
+		m.err = nil
+		m.editing = true
+		m.editor.SetValue(m.items[m.cursor].Comment)
+		return m, m.editor.Focus()
+	case "g":
+		if m.busy || m.regenerate == nil {
+			return m, nil
+		}
+		m.busy = true
+		m.err = nil
+		return m, m.regenerateCmd(m.cursor)
+	case "right", "l", "down", "j", "tab":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "left", "h", "up", "k", "shift+tab":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	}
+
+	return m, nil
+}
+
+// advance moves to the next undecided item, staying put once every item has
+// been decided so the user can still revisit them before writing.
+func (m *ReviewModel) advance() {
+	if next := m.nextPending(m.cursor); next >= 0 {
+		m.cursor = next
+	}
+}
+
+func (m ReviewModel) nextPending(from int) int {
+	for i := 1; i <= len(m.items); i++ {
+		idx := (from + i) % len(m.items)
+		if m.items[idx].Status == ReviewPending {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (m ReviewModel) counts() (accepted, rejected, pending int) {
+	for _, item := range m.items {
+		switch item.Status {
+		case ReviewAccepted:
+			accepted++
+		case ReviewRejected:
+			rejected++
+		default:
+			pending++
+		}
+	}
+	return accepted, rejected, pending
+}
+
+func (m ReviewModel) View() string {
+	if m.quitting || m.finished {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString(TitleStyle.UnsetMarginBottom().Render("annotr review") + " " + DimStyle.Render(m.file) + "\n\n")
+
+	if len(m.items) == 0 {
+		b.WriteString("No comments to review.\n")
+		b.WriteString(HelpStyle.Render("press any key to continue"))
+		return b.String()
+	}
+
+	item := m.items[m.cursor]
+	accepted, rejected, pending := m.counts()
+	b.WriteString(fmt.Sprintf("%s  %s %d  %s %d  %s %d\n\n",
+		DimStyle.Render(fmt.Sprintf("%d/%d", m.cursor+1, len(m.items))),
+		Checkmark(), accepted, Cross(), rejected, Bullet(), pending))
+
+	b.WriteString(SelectedStyle.Render(item.Name) + DimStyle.Render(fmt.Sprintf(" · line %d · ", item.Line)) + statusLabel(item.Status) + "\n\n")
+
+	if m.editing {
+		b.WriteString(m.editor.View() + "\n")
+	} else {
+		b.WriteString(InputStyle.Render(item.Comment) + "\n")
+	}
+	b.WriteString(DimStyle.Render(truncateLines(item.Code, maxReviewCodeLines)) + "\n")
+
+	if m.busy {
+		b.WriteString("\n" + WarningStyle.Render("Regenerating...") + "\n")
+	}
+	if m.err != nil {
+		b.WriteString("\n" + ErrorStyle.Render("Error: "+m.err.Error()) + "\n")
+	}
+	if pending == 0 {
+		b.WriteString("\n" + SuccessStyle.Render("All comments reviewed, press w to write.") + "\n")
+	}
+
+	if m.editing {
+		b.WriteString(HelpStyle.Render("ctrl+s save and accept • esc cancel"))
+	} else {
+		b.WriteString(HelpStyle.Render("a accept • r reject • e edit • g regenerate • A accept rest • ←/→ navigate • w write • q quit and resume later"))
+	}
+
+	return b.String()
+}
+
+// Items returns the items with the decisions and edits made so far.
+func (m ReviewModel) Items() []ReviewItem {
+	return m.items
+}
+
+// Finished reports whether the user asked to write the accepted comments, as
+// opposed to quitting part way through.
+func (m ReviewModel) Finished() bool {
+	return m.finished
+}
+
+func statusLabel(status string) string {
+	switch status {
+	case ReviewAccepted:
+		return SuccessStyle.Render("accepted")
+	case ReviewRejected:
+		return ErrorStyle.Render("rejected")
+	default:
+		return WarningStyle.Render("pending")
+	}
+}
+
+func truncateLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n… %d more lines", len(lines)-n)
+}